@@ -1,15 +1,20 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
+	// タイムゾーンデータベースのない環境でもユーザーのタイムゾーンを扱えるよう埋め込む
+	_ "time/tzdata"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/gin-gonic/gin"
 	"github.com/nana743533/260219-diary-app/server/internal/config"
 	"github.com/nana743533/260219-diary-app/server/internal/handler"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
+	"github.com/nana743533/260219-diary-app/server/internal/migrate"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
 	"github.com/nana743533/260219-diary-app/server/internal/service"
 )
 
@@ -19,54 +24,135 @@ func main() {
 		log.Fatal("Failed to load config:", err)
 	}
 
-	db, err := sql.Open("mysql", cfg.Database.DSN())
+	// DB接続・スキーマ確認
+	store, err := repository.Open(cfg.Database)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatal("Failed to ping database:", err)
+	if err := migrateDB(store, cfg.Database); err != nil {
+		log.Fatal("Failed to migrate database:", err)
 	}
 
-	// テーブル初期化
-	if err := initDB(db); err != nil {
+	if err := initDB(store, cfg.Auth); err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
-	diaryService := service.NewDiaryService(db)
+	diaryService := service.NewDiaryService(store.Diaries, store.Users, store.StreakFreezes, store.Tags, store.Metrics, store.SearchIndex, store)
+	userService := service.NewUserService(store.Users)
+	tagService := service.NewTagService(store.Tags)
+	metricService := service.NewMetricService(store.Metrics)
+	authService := service.NewAuthService(store.Users, store.RefreshTokens, cfg.Auth.JWTSecret, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
 
+	// 検索機能の追加前に作成された日記を索引に登録する
+	indexed, err := diaryService.BuildSearchIndex()
+	if err != nil {
+		log.Fatal("Failed to build search index:", err)
+	}
+	if indexed > 0 {
+		log.Printf("Indexed %d diary memo(s) for search", indexed)
+	}
+
+	authHandler := handler.NewAuthHandler(authService)
+	userHandler := handler.NewUserHandler(userService)
 	diaryHandler := handler.NewDiaryHandler(diaryService)
+	tagHandler := handler.NewTagHandler(tagService)
+	metricHandler := handler.NewMetricHandler(metricService)
 	calendarHandler := handler.NewCalendarHandler(diaryService)
 	statsHandler := handler.NewStatisticsHandler(diaryService)
+	reportHandler := handler.NewReportHandler(diaryService)
+
+	if err := handler.RegisterValidators(); err != nil {
+		log.Fatal("Failed to register validators:", err)
+	}
 
 	r := gin.Default()
 
 	// ルーティング
 	v1 := r.Group("/api/v1")
 	{
+		// 認証エンドポイント
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/register", authHandler.Register)
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.Refresh)
+		}
+
+		// 以降は認証必須
+		protected := v1.Group("")
+		if cfg.Auth.Disabled {
+			protected.Use(middleware.Anonymous(cfg.Auth.DefaultUserID))
+		} else {
+			protected.Use(middleware.Auth(authService))
+		}
+
+		protected.POST("/auth/logout", authHandler.Logout)
+		protected.GET("/auth/sessions", authHandler.GetSessions)
+		protected.DELETE("/auth/sessions/:id", authHandler.RevokeSession)
+
+		// ユーザー設定エンドポイント
+		protected.GET("/users/me/settings", userHandler.GetSettings)
+		protected.PATCH("/users/me/settings", userHandler.UpdateSettings)
+
 		// 日記エンドポイント
-		diaries := v1.Group("/diaries")
+		diaries := protected.Group("/diaries")
 		{
 			diaries.POST("", diaryHandler.Create)
 			diaries.GET("", diaryHandler.GetAll)
+			diaries.GET("/search", diaryHandler.Search)
 			diaries.GET("/:date", diaryHandler.GetByDate)
 			diaries.PUT("/:date", diaryHandler.Update)
 			diaries.DELETE("/:date", diaryHandler.Delete)
 		}
 
+		// タグエンドポイント
+		tags := protected.Group("/tags")
+		{
+			tags.GET("", tagHandler.List)
+			tags.PATCH("/:id", tagHandler.Rename)
+			tags.POST("/:id/merge", tagHandler.Merge)
+			tags.DELETE("/:id", tagHandler.Delete)
+		}
+
+		// 記録項目エンドポイント
+		metrics := protected.Group("/metrics")
+		{
+			metrics.GET("", metricHandler.List)
+			metrics.POST("", metricHandler.Create)
+			metrics.GET("/:id", metricHandler.Get)
+			metrics.PUT("/:id", metricHandler.Update)
+			metrics.DELETE("/:id", metricHandler.Delete)
+		}
+
 		// カレンダーエンドポイント
-		calendar := v1.Group("/calendar")
+		calendar := protected.Group("/calendar")
 		{
 			calendar.GET("", calendarHandler.GetRange)
+			calendar.GET("/week", calendarHandler.GetWeekOf)
+			calendar.GET("/:year", calendarHandler.GetYear)
 			calendar.GET("/:year/:month", calendarHandler.GetMonth)
+			calendar.GET("/:year/week/:isoweek", calendarHandler.GetWeek)
 		}
 
 		// 統計エンドポイント
-		stats := v1.Group("/statistics")
+		stats := protected.Group("/statistics")
 		{
 			stats.GET("/summary", statsHandler.GetSummary)
 			stats.GET("/trend", statsHandler.GetTrend)
+			stats.GET("/patterns", statsHandler.GetPatterns)
+			stats.GET("/insights", statsHandler.GetInsights)
+			stats.GET("/sleep", statsHandler.GetSleep)
+			stats.GET("/streaks", statsHandler.GetStreaks)
+			stats.POST("/streaks/freezes", statsHandler.CreateStreakFreeze)
+			stats.DELETE("/streaks/freezes/:date", statsHandler.DeleteStreakFreeze)
+		}
+
+		// レポートエンドポイント
+		reports := protected.Group("/reports")
+		{
+			reports.GET("/year/:year", reportHandler.GetYear)
 		}
 	}
 
@@ -75,7 +161,7 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{
 			"status": "ok",
 			"database": func() string {
-				if err := db.Ping(); err != nil {
+				if err := store.Ping(); err != nil {
 					return "error"
 				}
 				return "ok"
@@ -88,50 +174,54 @@ func main() {
 	log.Fatal(r.Run(addr))
 }
 
-func initDB(db *sql.DB) error {
-	// ユーザーテーブル（認証なしの場合はダミー）
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS users (
-			id VARCHAR(36) PRIMARY KEY,
-			username VARCHAR(30) NOT NULL,
-			email VARCHAR(255) NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
-		)
-	`)
+// migrateDB はスキーマのバージョンを確認し、AutoMigrate が有効なら未適用分を適用する。
+// データベースがこのバイナリより新しいスキーマの場合は起動しない。
+func migrateDB(store *repository.Store, dbCfg config.DatabaseConfig) error {
+	if store.DB() == nil {
+		return nil
+	}
+
+	m, err := migrate.New(store.DB(), store.Driver())
 	if err != nil {
 		return err
 	}
+	if err := m.Check(); err != nil {
+		return err
+	}
 
-	// デフォルトユーザーを挿入
-	_, err = db.Exec(`
-		INSERT IGNORE INTO users (id, username, email)
-		VALUES ('default-user', 'default', 'default@example.com')
-	`)
+	if dbCfg.AutoMigrate {
+		applied, err := m.Up()
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			log.Printf("Applied %d migration(s), schema is at version %d", applied, m.Latest())
+		}
+		return nil
+	}
+
+	version, err := m.Version()
 	if err != nil {
 		return err
 	}
+	if version < m.Latest() {
+		return fmt.Errorf("schema is at version %d but %d is required; run `migrate up`", version, m.Latest())
+	}
+	return nil
+}
+
+func initDB(store *repository.Store, authCfg config.AuthConfig) error {
+	// 認証なしモードではデフォルトユーザーを挿入（パスワードなしのためログイン不可）
+	if !authCfg.Disabled {
+		return nil
+	}
 
-	// 日記テーブル
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS diaries (
-			id VARCHAR(36) PRIMARY KEY,
-			user_id VARCHAR(36) NOT NULL,
-			date DATE NOT NULL,
-			rating INT NOT NULL CHECK (rating >= 1 AND rating <= 5),
-			progress VARCHAR(1) NOT NULL CHECK (progress IN ('A', 'B', 'C')),
-			wake_up_time VARCHAR(5) NOT NULL,
-			sleep_time VARCHAR(5) NOT NULL,
-			memo TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-			UNIQUE KEY unique_user_date (user_id, date),
-			INDEX idx_user_id (user_id),
-			INDEX idx_date (date),
-			INDEX idx_user_date (user_id, date)
-		)
-	`)
-
-	return err
+	now := time.Now().UTC()
+	return store.Users.EnsureExists(&model.User{
+		ID:        authCfg.DefaultUserID,
+		Username:  "default",
+		Email:     "default@example.com",
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
 }
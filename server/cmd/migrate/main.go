@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/nana743533/260219-diary-app/server/internal/config"
+	"github.com/nana743533/260219-diary-app/server/internal/migrate"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up         未適用のマイグレーションをすべて適用する
+  down [N]   適用済みのマイグレーションを N 件（デフォルト 1）ロールバックする
+  status     各マイグレーションの適用状況を表示する`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	dbCfg := config.LoadDatabase()
+	if dbCfg.Driver == "memory" {
+		log.Fatal("DB_DRIVER=memory has no schema to migrate")
+	}
+
+	store, err := repository.Open(dbCfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer store.Close()
+
+	m, err := migrate.New(store.DB(), store.Driver())
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := m.Up()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatal("N must be a positive integer")
+			}
+		}
+		reverted, err := m.Down(steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%-8s %-40s %s\n", "VERSION", "NAME", "APPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d     %-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
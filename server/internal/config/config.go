@@ -2,14 +2,18 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"net"
+	"net/url"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Auth     AuthConfig
 }
 
 type ServerConfig struct {
@@ -17,40 +21,125 @@ type ServerConfig struct {
 }
 
 type DatabaseConfig struct {
+	Driver   string // mysql | postgres | sqlite | memory
+	Path     string // SQLite のデータベースファイル
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Database string
+	SSLMode  string // PostgreSQL のみ
+	// AutoMigrate が true の場合は起動時に未適用のマイグレーションを適用する
+	AutoMigrate bool
 }
 
-func Load() (*Config, error) {
+type AuthConfig struct {
+	// Disabled が true の場合は認証を行わず、固定の DefaultUserID を使用する（ローカル開発用）
+	Disabled        bool
+	DefaultUserID   string
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+func setDefaults() {
 	viper.SetDefault("SERVER_PORT", "8080")
+	viper.SetDefault("DB_DRIVER", "mysql")
+	viper.SetDefault("DB_PATH", "diary.db")
 	viper.SetDefault("DB_HOST", "localhost")
-	viper.SetDefault("DB_PORT", "3306")
+	viper.SetDefault("DB_PORT", "")
 	viper.SetDefault("DB_USER", "root")
 	viper.SetDefault("DB_PASSWORD", "")
 	viper.SetDefault("DB_NAME", "diary_app")
+	viper.SetDefault("DB_SSLMODE", "disable")
+	viper.SetDefault("DB_AUTO_MIGRATE", true)
+	viper.SetDefault("AUTH_DISABLED", false)
+	viper.SetDefault("AUTH_DEFAULT_USER_ID", "default-user")
+	viper.SetDefault("JWT_SECRET", "")
+	viper.SetDefault("JWT_EXPIRES_IN", "24h")
+	viper.SetDefault("JWT_REFRESH_EXPIRES_IN", "720h")
 
 	viper.AutomaticEnv()
+}
+
+// LoadDatabase はデータベースの設定のみを読み込む（migrate コマンド用）
+func LoadDatabase() DatabaseConfig {
+	setDefaults()
+
+	db := DatabaseConfig{
+		Driver:      viper.GetString("DB_DRIVER"),
+		Path:        viper.GetString("DB_PATH"),
+		Host:        viper.GetString("DB_HOST"),
+		Port:        viper.GetString("DB_PORT"),
+		User:        viper.GetString("DB_USER"),
+		Password:    viper.GetString("DB_PASSWORD"),
+		Database:    viper.GetString("DB_NAME"),
+		SSLMode:     viper.GetString("DB_SSLMODE"),
+		AutoMigrate: viper.GetBool("DB_AUTO_MIGRATE"),
+	}
+	if db.Port == "" {
+		db.Port = defaultPort(db.Driver)
+	}
+
+	return db
+}
+
+func Load() (*Config, error) {
+	setDefaults()
 
 	cfg := &Config{
 		Server: ServerConfig{
 			Port: viper.GetString("SERVER_PORT"),
 		},
-		Database: DatabaseConfig{
-			Host:     viper.GetString("DB_HOST"),
-			Port:     viper.GetString("DB_PORT"),
-			User:     viper.GetString("DB_USER"),
-			Password: viper.GetString("DB_PASSWORD"),
-			Database: viper.GetString("DB_NAME"),
+		Database: LoadDatabase(),
+		Auth: AuthConfig{
+			Disabled:        viper.GetBool("AUTH_DISABLED"),
+			DefaultUserID:   viper.GetString("AUTH_DEFAULT_USER_ID"),
+			JWTSecret:       viper.GetString("JWT_SECRET"),
+			AccessTokenTTL:  viper.GetDuration("JWT_EXPIRES_IN"),
+			RefreshTokenTTL: viper.GetDuration("JWT_REFRESH_EXPIRES_IN"),
 		},
 	}
 
+	// PostgreSQL の users.id は UUID 型
+	if cfg.Database.Driver == "postgres" && cfg.Auth.Disabled {
+		if _, err := uuid.Parse(cfg.Auth.DefaultUserID); err != nil {
+			return nil, fmt.Errorf("AUTH_DEFAULT_USER_ID must be a UUID when DB_DRIVER=postgres")
+		}
+	}
+
+	if !cfg.Auth.Disabled && cfg.Auth.JWTSecret == "" {
+		return nil, fmt.Errorf("JWT_SECRET is required unless AUTH_DISABLED=true")
+	}
+	if cfg.Auth.AccessTokenTTL <= 0 || cfg.Auth.RefreshTokenTTL <= 0 {
+		return nil, fmt.Errorf("JWT_EXPIRES_IN and JWT_REFRESH_EXPIRES_IN must be positive durations")
+	}
+
 	return cfg, nil
 }
 
+func defaultPort(driver string) string {
+	if driver == "postgres" {
+		return "5432"
+	}
+	return "3306"
+}
+
 func (c *DatabaseConfig) DSN() string {
+	if c.Driver == "postgres" {
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.User, c.Password),
+			Host:     net.JoinHostPort(c.Host, c.Port),
+			Path:     "/" + c.Database,
+			RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+		}
+		if c.Password == "" {
+			u.User = url.User(c.User)
+		}
+		return u.String()
+	}
+
 	if c.Password == "" {
 		return fmt.Sprintf("%s@tcp(%s:%s)/%s?parseTime=true",
 			c.User, c.Host, c.Port, c.Database)
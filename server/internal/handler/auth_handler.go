@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/service"
+)
+
+type AuthHandler struct {
+	service *service.AuthService
+}
+
+func NewAuthHandler(service *service.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req model.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	res, err := h.service.Register(req, clientInfo(c))
+	if err != nil {
+		respondError(c, err, "Failed to register user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	res, err := h.service.Login(req, clientInfo(c))
+	if err != nil {
+		respondError(c, err, "Failed to login")
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	token, err := h.service.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		respondError(c, err, "Failed to refresh token")
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID := middleware.UserID(c)
+	sessionID := middleware.SessionID(c)
+
+	if err := h.service.Logout(userID, sessionID); err != nil {
+		respondError(c, err, "Failed to logout")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	sessions, err := h.service.GetSessions(userID, middleware.SessionID(c))
+	if err != nil {
+		respondError(c, err, "Failed to fetch sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+	})
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	err := h.service.RevokeSession(userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to revoke session")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
 	"github.com/nana743533/260219-diary-app/server/internal/model"
 	"github.com/nana743533/260219-diary-app/server/internal/service"
 )
@@ -18,7 +19,7 @@ func NewCalendarHandler(service *service.DiaryService) *CalendarHandler {
 }
 
 func (h *CalendarHandler) GetMonth(c *gin.Context) {
-	userID := "default-user"
+	userID := middleware.UserID(c)
 
 	year, err := strconv.Atoi(c.Param("year"))
 	if err != nil {
@@ -44,51 +45,95 @@ func (h *CalendarHandler) GetMonth(c *gin.Context) {
 
 	data, err := h.service.GetCalendarData(userID, year, month)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+		respondError(c, err, "Failed to fetch calendar data")
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// GetYear は年間ヒートマップを返す（metric で濃さの基準を選ぶ）
+func (h *CalendarHandler) GetYear(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to fetch calendar data",
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid year",
 			},
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, data)
-}
+	heatmap, err := h.service.GetYearHeatmap(userID, year, c.Query("metric"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch heatmap data")
+		return
+	}
 
-func (h *CalendarHandler) GetRange(c *gin.Context) {
-	userID := "default-user"
+	c.JSON(http.StatusOK, heatmap)
+}
 
-	startDate := c.Query("start_date")
-	endDate := c.Query("end_date")
+// GetWeek は ISO 8601 の週の7日分を返す
+func (h *CalendarHandler) GetWeek(c *gin.Context) {
+	userID := middleware.UserID(c)
 
-	if startDate == "" || endDate == "" {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: model.ErrorDetail{
 				Code:    "VALIDATION_ERROR",
-				Message: "start_date and end_date are required",
+				Message: "Invalid year",
 			},
 		})
 		return
 	}
 
-	diaries, err := h.service.GetAll(userID, startDate, endDate, 1000, 0)
+	week, err := strconv.Atoi(c.Param("isoweek"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to fetch calendar data",
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid week",
 			},
 		})
 		return
 	}
 
-	entries := make([]model.CalendarEntry, len(diaries))
-	for i, d := range diaries {
-		entries[i] = model.CalendarEntry{
-			Date:   d.Date,
-			Rating: d.Rating,
-		}
+	view, err := h.service.GetWeek(userID, year, week)
+	if err != nil {
+		respondError(c, err, "Failed to fetch calendar data")
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// GetWeekOf は date（省略時は今日）を含む週の7日分を返す
+func (h *CalendarHandler) GetWeekOf(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	view, err := h.service.GetWeekOf(userID, middleware.Timezone(c), c.Query("date"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch calendar data")
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+func (h *CalendarHandler) GetRange(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	entries, err := h.service.GetCalendarRange(userID, startDate, endDate, c.Query("tags"), c.Query("tag_match"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch calendar data")
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
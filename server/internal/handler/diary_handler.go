@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
 	"github.com/nana743533/260219-diary-app/server/internal/model"
 	"github.com/nana743533/260219-diary-app/server/internal/service"
 )
@@ -18,28 +19,17 @@ func NewDiaryHandler(service *service.DiaryService) *DiaryHandler {
 }
 
 func (h *DiaryHandler) Create(c *gin.Context) {
-	// 認証なしバージョン: 固定のユーザーIDを使用
-	userID := "default-user"
+	userID := middleware.UserID(c)
 
 	var req model.CreateDiaryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "VALIDATION_ERROR",
-				Message: err.Error(),
-			},
-		})
+		respondValidationError(c, err)
 		return
 	}
 
-	diary, err := h.service.Create(userID, req)
+	diary, err := h.service.Create(userID, middleware.Timezone(c), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to create diary",
-			},
-		})
+		respondError(c, err, "Failed to create diary")
 		return
 	}
 
@@ -47,56 +37,73 @@ func (h *DiaryHandler) Create(c *gin.Context) {
 }
 
 func (h *DiaryHandler) GetAll(c *gin.Context) {
-	userID := "default-user"
+	userID := middleware.UserID(c)
 
-	startDate := c.Query("start_date")
-	endDate := c.Query("end_date")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	var q model.ListDiariesQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		respondValidationError(c, err)
+		return
+	}
 
-	diaries, err := h.service.GetAll(userID, startDate, endDate, limit, offset)
+	list, err := h.service.GetAll(userID, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to fetch diaries",
-			},
-		})
+		respondError(c, err, "Failed to fetch diaries")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"diaries": diaries,
-		"pagination": gin.H{
-			"total":  len(diaries),
-			"limit":  limit,
-			"offset": offset,
-		},
-	})
+	// 次のページの URL は現在のクエリの offset / cursor だけを差し替える
+	if list.Pagination.HasMore {
+		query := c.Request.URL.Query()
+		if list.Pagination.NextCursor != nil {
+			query.Set("cursor", *list.Pagination.NextCursor)
+		} else {
+			query.Set("offset", strconv.Itoa(list.Pagination.Offset+len(list.Diaries)))
+		}
+		query.Set("limit", strconv.Itoa(list.Pagination.Limit))
+		next := c.Request.URL.Path + "?" + query.Encode()
+		list.Pagination.Next = &next
+	}
+
+	c.JSON(http.StatusOK, list)
 }
 
-func (h *DiaryHandler) GetByDate(c *gin.Context) {
-	userID := "default-user"
-	date := c.Param("date")
+// Search はメモの全文検索の結果を関連の高い順に返す
+func (h *DiaryHandler) Search(c *gin.Context) {
+	userID := middleware.UserID(c)
 
-	diary, err := h.service.GetByDate(userID, date)
+	var q model.SearchDiariesQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	result, err := h.service.Search(userID, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to fetch diary",
-			},
-		})
+		respondError(c, err, "Failed to search diaries")
 		return
 	}
 
-	if diary == nil {
-		c.JSON(http.StatusNotFound, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "NOT_FOUND",
-				Message: "Diary not found",
-			},
-		})
+	if result.Pagination.HasMore {
+		query := c.Request.URL.Query()
+		query.Set("offset", strconv.Itoa(result.Pagination.Offset+len(result.Results)))
+		query.Set("limit", strconv.Itoa(result.Pagination.Limit))
+		next := c.Request.URL.Path + "?" + query.Encode()
+		result.Pagination.Next = &next
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *DiaryHandler) GetByDate(c *gin.Context) {
+	userID := middleware.UserID(c)
+	date, ok := dateParam(c)
+	if !ok {
+		return
+	}
+
+	diary, err := h.service.GetByDate(userID, date)
+	if err != nil {
+		respondError(c, err, "Failed to fetch diary")
 		return
 	}
 
@@ -104,28 +111,21 @@ func (h *DiaryHandler) GetByDate(c *gin.Context) {
 }
 
 func (h *DiaryHandler) Update(c *gin.Context) {
-	userID := "default-user"
-	date := c.Param("date")
+	userID := middleware.UserID(c)
+	date, ok := dateParam(c)
+	if !ok {
+		return
+	}
 
 	var req model.UpdateDiaryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "VALIDATION_ERROR",
-				Message: err.Error(),
-			},
-		})
+		respondValidationError(c, err)
 		return
 	}
 
 	diary, err := h.service.Update(userID, date, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to update diary",
-			},
-		})
+		respondError(c, err, "Failed to update diary")
 		return
 	}
 
@@ -133,17 +133,15 @@ func (h *DiaryHandler) Update(c *gin.Context) {
 }
 
 func (h *DiaryHandler) Delete(c *gin.Context) {
-	userID := "default-user"
-	date := c.Param("date")
+	userID := middleware.UserID(c)
+	date, ok := dateParam(c)
+	if !ok {
+		return
+	}
 
 	err := h.service.Delete(userID, date)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to delete diary",
-			},
-		})
+		respondError(c, err, "Failed to delete diary")
 		return
 	}
 
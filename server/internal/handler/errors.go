@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/service"
+)
+
+// respondError はサービスエラーを API_SPEC.md のエラーコード表に沿ったレスポンスに変換する。
+// 想定外のエラーは 500 とし、詳細はクライアントに返さずに message を使う。
+func respondError(c *gin.Context, err error, message string) {
+	var serviceErr *service.Error
+	if errors.As(err, &serviceErr) {
+		c.JSON(statusFor(serviceErr.Kind), model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    serviceErr.Code,
+				Message: serviceErr.Message,
+				Details: serviceErr.Details,
+			},
+		})
+		return
+	}
+
+	_ = c.Error(err)
+	c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+		Error: model.ErrorDetail{
+			Code:    "INTERNAL_ERROR",
+			Message: message,
+		},
+	})
+}
+
+// respondValidationError はリクエストのバインドに失敗した場合のレスポンスを返す。
+// バリデーションエラーの場合はフィールドごとの内容を Details に入れる。
+func respondValidationError(c *gin.Context, err error) {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	details := make(map[string]string, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		details[fe.Field()] = fieldErrorMessage(fe)
+	}
+	c.JSON(http.StatusBadRequest, model.ErrorResponse{
+		Error: model.ErrorDetail{
+			Code:    "VALIDATION_ERROR",
+			Message: "Validation failed",
+			Details: details,
+		},
+	})
+}
+
+func statusFor(kind error) int {
+	switch kind {
+	case service.ErrNotFound:
+		return http.StatusNotFound
+	case service.ErrConflict:
+		return http.StatusConflict
+	case service.ErrValidation:
+		return http.StatusBadRequest
+	case service.ErrUnauthorized:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/service"
+)
+
+type MetricHandler struct {
+	service *service.MetricService
+}
+
+func NewMetricHandler(service *service.MetricService) *MetricHandler {
+	return &MetricHandler{service: service}
+}
+
+func (h *MetricHandler) List(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	metrics, err := h.service.List(userID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch metrics")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"metrics": metrics})
+}
+
+func (h *MetricHandler) Create(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	var req model.CreateMetricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	metric, err := h.service.Create(userID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create metric")
+		return
+	}
+
+	c.JSON(http.StatusCreated, metric)
+}
+
+func (h *MetricHandler) Get(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	metric, err := h.service.Get(userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch metric")
+		return
+	}
+
+	c.JSON(http.StatusOK, metric)
+}
+
+// Update は名前・単位・範囲を置き換える（種類は変更できない）
+func (h *MetricHandler) Update(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	var req model.UpdateMetricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	metric, err := h.service.Update(userID, c.Param("id"), req)
+	if err != nil {
+		respondError(c, err, "Failed to update metric")
+		return
+	}
+
+	c.JSON(http.StatusOK, metric)
+}
+
+func (h *MetricHandler) Delete(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	if err := h.service.Delete(userID, c.Param("id")); err != nil {
+		respondError(c, err, "Failed to delete metric")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/service"
+)
+
+type ReportHandler struct {
+	service *service.DiaryService
+}
+
+func NewReportHandler(service *service.DiaryService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+func (h *ReportHandler) GetYear(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid year",
+			},
+		})
+		return
+	}
+
+	report, err := h.service.GetYearReport(userID, middleware.Timezone(c), year)
+	if err != nil {
+		respondError(c, err, "Failed to build year report")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
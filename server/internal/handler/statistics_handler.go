@@ -2,13 +2,20 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
 	"github.com/nana743533/260219-diary-app/server/internal/model"
 	"github.com/nana743533/260219-diary-app/server/internal/service"
 )
 
+const (
+	// defaultStatisticsDays は days パラメータを省略した場合の日数
+	defaultStatisticsDays = 30
+	// maxStatisticsDays は days パラメータの上限
+	maxStatisticsDays = 365
+)
+
 type StatisticsHandler struct {
 	service *service.DiaryService
 }
@@ -18,41 +25,146 @@ func NewStatisticsHandler(service *service.DiaryService) *StatisticsHandler {
 }
 
 func (h *StatisticsHandler) GetSummary(c *gin.Context) {
-	userID := "default-user"
-	period := c.DefaultQuery("period", "month")
+	userID := middleware.UserID(c)
+	period := c.Query("period")
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	compare := c.Query("compare")
 
-	stats, err := h.service.GetStatistics(userID, period)
+	stats, err := h.service.GetStatistics(userID, middleware.Timezone(c), period, startDate, endDate, compare)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to fetch statistics",
-			},
-		})
+		respondError(c, err, "Failed to fetch statistics")
 		return
 	}
 
 	c.JSON(http.StatusOK, stats)
 }
 
+func (h *StatisticsHandler) GetPatterns(c *gin.Context) {
+	userID := middleware.UserID(c)
+	period := c.Query("period")
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	patterns, err := h.service.GetPatterns(userID, middleware.Timezone(c), period, startDate, endDate)
+	if err != nil {
+		respondError(c, err, "Failed to fetch patterns")
+		return
+	}
+
+	c.JSON(http.StatusOK, patterns)
+}
+
+func (h *StatisticsHandler) GetInsights(c *gin.Context) {
+	userID := middleware.UserID(c)
+	period := c.Query("period")
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	// 相関は標本が多いほど信頼できるため、期間未指定の場合は全期間を使う
+	if period == "" && startDate == "" && endDate == "" {
+		period = "all"
+	}
+
+	insights, err := h.service.GetInsights(userID, middleware.Timezone(c), period, startDate, endDate)
+	if err != nil {
+		respondError(c, err, "Failed to fetch insights")
+		return
+	}
+
+	c.JSON(http.StatusOK, insights)
+}
+
 func (h *StatisticsHandler) GetTrend(c *gin.Context) {
-	userID := "default-user"
-	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	userID := middleware.UserID(c)
+	days, ok := intQuery(c, "days", defaultStatisticsDays, 1, maxStatisticsDays)
+	if !ok {
+		return
+	}
+	// rolling の値（7 / 30）はサービス側で検証する
+	rolling, ok := intQuery(c, "rolling", 0, 0, maxStatisticsDays)
+	if !ok {
+		return
+	}
 
-	if days <= 0 {
-		days = 30
+	opts := service.TrendOptions{
+		Days:    days,
+		Fill:    c.Query("fill") == "true",
+		Bucket:  c.Query("bucket"),
+		Rolling: rolling,
 	}
 
-	trend, err := h.service.GetTrend(userID, days)
+	trend, err := h.service.GetTrend(userID, middleware.Timezone(c), opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "INTERNAL_ERROR",
-				Message: "Failed to fetch trend data",
-			},
-		})
+		respondError(c, err, "Failed to fetch trend data")
 		return
 	}
 
 	c.JSON(http.StatusOK, trend)
 }
+
+func (h *StatisticsHandler) GetSleep(c *gin.Context) {
+	userID := middleware.UserID(c)
+	days, ok := intQuery(c, "days", defaultStatisticsDays, 1, maxStatisticsDays)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetSleepStatistics(userID, middleware.Timezone(c), days)
+	if err != nil {
+		respondError(c, err, "Failed to fetch sleep statistics")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *StatisticsHandler) GetStreaks(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	status, err := h.service.GetStreaks(userID, middleware.Timezone(c))
+	if err != nil {
+		respondError(c, err, "Failed to fetch streaks")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *StatisticsHandler) CreateStreakFreeze(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	var req model.CreateStreakFreezeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.service.CreateStreakFreeze(userID, middleware.Timezone(c), req.Date); err != nil {
+		respondError(c, err, "Failed to freeze streak")
+		return
+	}
+
+	status, err := h.service.GetStreaks(userID, middleware.Timezone(c))
+	if err != nil {
+		respondError(c, err, "Failed to fetch streaks")
+		return
+	}
+
+	c.JSON(http.StatusCreated, status)
+}
+
+func (h *StatisticsHandler) DeleteStreakFreeze(c *gin.Context) {
+	userID := middleware.UserID(c)
+	date, ok := dateParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteStreakFreeze(userID, date); err != nil {
+		respondError(c, err, "Failed to delete streak freeze")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
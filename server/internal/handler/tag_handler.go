@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/service"
+)
+
+type TagHandler struct {
+	service *service.TagService
+}
+
+func NewTagHandler(service *service.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+func (h *TagHandler) List(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	tags, err := h.service.List(userID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func (h *TagHandler) Rename(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	var req model.RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	tag, err := h.service.Rename(userID, c.Param("id"), req)
+	if err != nil {
+		respondError(c, err, "Failed to rename tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Merge は :id のタグを target_id のタグに統合する
+func (h *TagHandler) Merge(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	var req model.MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	tag, err := h.service.Merge(userID, c.Param("id"), req)
+	if err != nil {
+		respondError(c, err, "Failed to merge tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) Delete(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	if err := h.service.Delete(userID, c.Param("id")); err != nil {
+		respondError(c, err, "Failed to delete tag")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
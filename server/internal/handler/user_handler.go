@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/middleware"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/service"
+)
+
+type UserHandler struct {
+	service *service.UserService
+}
+
+func NewUserHandler(service *service.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+func (h *UserHandler) GetSettings(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	settings, err := h.service.GetSettings(userID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *UserHandler) UpdateSettings(c *gin.Context) {
+	userID := middleware.UserID(c)
+
+	var req model.UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(userID, req)
+	if err != nil {
+		respondError(c, err, "Failed to update settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// maxTagNameLength はタグ名の最大文字数
+	maxTagNameLength = 30
+)
+
+var (
+	minDiaryDate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	hhmmPattern  = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+)
+
+// RegisterValidators は hhmm / diarydate / tzname / tagname のカスタムバリデーターを登録し、
+// エラー詳細のフィールド名に JSON 名を使うよう設定する
+func RegisterValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected validator engine")
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	if err := v.RegisterValidation("hhmm", func(fl validator.FieldLevel) bool {
+		return hhmmPattern.MatchString(fl.Field().String())
+	}); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("diarydate", func(fl validator.FieldLevel) bool {
+		return diaryDateError(fl.Field().String()) == ""
+	}); err != nil {
+		return err
+	}
+	// tzname は空文字（未設定）または IANA タイムゾーン名。"Local" はサーバー依存になるため認めない
+	if err := v.RegisterValidation("tzname", func(fl validator.FieldLevel) bool {
+		name := fl.Field().String()
+		if name == "" {
+			return true
+		}
+		if name == "Local" {
+			return false
+		}
+		_, err := time.LoadLocation(name)
+		return err == nil
+	}); err != nil {
+		return err
+	}
+	// tagname は前後の空白を除いて1〜30文字。一覧の絞り込みでカンマ区切りにするためカンマは使えない
+	return v.RegisterValidation("tagname", func(fl validator.FieldLevel) bool {
+		name := strings.TrimSpace(fl.Field().String())
+		return name != "" && utf8.RuneCountInString(name) <= maxTagNameLength && !strings.Contains(name, ",")
+	})
+}
+
+// diaryDateError は日記の日付として不正な場合にその理由を返す（正しい場合は空文字）。
+// 未来の日付かどうかはユーザーのタイムゾーンで決まるため、ここでは検証せずサービスで検証する。
+func diaryDateError(date string) string {
+	d, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return "YYYY-MM-DD形式の存在する日付で指定してください"
+	}
+	if d.Before(minDiaryDate) {
+		return fmt.Sprintf("%s以降の日付で指定してください", minDiaryDate.Format(dateLayout))
+	}
+	return ""
+}
+
+// intQuery はクエリパラメータ name を lo〜hi の整数として読む（省略時は def）。
+// 不正な場合は 400 を返し false を返す。
+func intQuery(c *gin.Context, name string, def, lo, hi int) (int, bool) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return def, true
+	}
+
+	var msg string
+	n, err := strconv.Atoi(raw)
+	switch {
+	case err != nil:
+		msg = "整数で指定してください"
+	case n < lo:
+		msg = fmt.Sprintf("%d以上で指定してください", lo)
+	case n > hi:
+		msg = fmt.Sprintf("%d以下で指定してください", hi)
+	}
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Validation failed",
+				Details: map[string]string{name: msg},
+			},
+		})
+		return 0, false
+	}
+	return n, true
+}
+
+// dateParam は :date パスパラメータを検証する。不正な場合は 400 を返し false を返す。
+func dateParam(c *gin.Context) (string, bool) {
+	date := c.Param("date")
+	if msg := diaryDateError(date); msg != "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid date",
+				Details: map[string]string{"date": msg},
+			},
+		})
+		return "", false
+	}
+	return date, true
+}
+
+// fieldErrorMessage はバリデーションタグごとのエラーメッセージを返す
+func fieldErrorMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "必須項目です"
+	case "hhmm":
+		return "HH:MM形式（00:00〜23:59）で指定してください"
+	case "diarydate":
+		return diaryDateError(fmt.Sprint(fe.Value()))
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s文字以上で指定してください", fe.Param())
+		}
+		return fmt.Sprintf("%s以上で指定してください", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s文字以下で指定してください", fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("%s個以下で指定してください", fe.Param())
+		}
+		return fmt.Sprintf("%s以下で指定してください", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s のいずれかで指定してください", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "email":
+		return "メールアドレスの形式で指定してください"
+	case "tzname":
+		return "IANA タイムゾーン名（例: Asia/Tokyo）で指定してください"
+	case "tagname":
+		return fmt.Sprintf("カンマを含まない1〜%d文字で指定してください", maxTagNameLength)
+	default:
+		return fmt.Sprintf("%s の条件を満たしていません", fe.Tag())
+	}
+}
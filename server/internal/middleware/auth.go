@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/service"
+)
+
+const (
+	userIDKey    = "user_id"
+	sessionIDKey = "session_id"
+)
+
+// Auth は Authorization: Bearer <token> を検証し、ユーザーIDをコンテキストに格納する
+func Auth(authService *service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "UNAUTHORIZED",
+					Message: "Authorization header is required",
+				},
+			})
+			return
+		}
+
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "INVALID_TOKEN",
+					Message: "Authorization header must be in the form 'Bearer <token>'",
+				},
+			})
+			return
+		}
+
+		userID, sessionID, err := authService.ParseAccessToken(strings.TrimSpace(token))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidToken) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, model.ErrorResponse{
+					Error: model.ErrorDetail{
+						Code:    "INVALID_TOKEN",
+						Message: "Invalid or expired token",
+					},
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "INTERNAL_ERROR",
+					Message: "Failed to verify token",
+				},
+			})
+			return
+		}
+
+		c.Set(userIDKey, userID)
+		c.Set(sessionIDKey, sessionID)
+		c.Next()
+	}
+}
+
+// Anonymous は認証を行わず、固定のユーザーIDをコンテキストに格納する（AUTH_DISABLED 用）
+func Anonymous(userID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(userIDKey, userID)
+		c.Next()
+	}
+}
+
+// UserID は Auth または Anonymous が格納したユーザーIDを返す
+func UserID(c *gin.Context) string {
+	return c.GetString(userIDKey)
+}
+
+// SessionID は Auth が格納したセッションIDを返す（Anonymous の場合は空文字）
+func SessionID(c *gin.Context) string {
+	return c.GetString(sessionIDKey)
+}
+
+// Timezone は X-Timezone ヘッダーで指定されたタイムゾーン名を返す（指定がない場合は空文字）。
+// ユーザー設定より優先され、AUTH_DISABLED で1人のユーザーを共有する場合などに使う。
+func Timezone(c *gin.Context) string {
+	return strings.TrimSpace(c.GetHeader("X-Timezone"))
+}
@@ -0,0 +1,280 @@
+package migrate
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/config"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+// newTestMigrator は一時ディレクトリの SQLite に対する Migrator を返す
+func newTestMigrator(t *testing.T) *Migrator {
+	t.Helper()
+	store, err := repository.Open(config.DatabaseConfig{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	m, err := New(store.DB(), store.Driver())
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	return m
+}
+
+// tables は schema_migrations 以外のテーブル名を返す
+func tables(t *testing.T, m *Migrator) []string {
+	t.Helper()
+	rows, err := m.db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations' ORDER BY name")
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func assertVersion(t *testing.T, m *Migrator, want int) {
+	t.Helper()
+	version, err := m.Version()
+	if err != nil {
+		t.Fatalf("version: %v", err)
+	}
+	if version != want {
+		t.Errorf("version = %d, want %d", version, want)
+	}
+}
+
+func TestUpDownUp(t *testing.T) {
+	m := newTestMigrator(t)
+	latest := m.Latest()
+	if latest == 0 {
+		t.Fatal("no migrations for sqlite")
+	}
+
+	applied, err := m.Up()
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if applied != latest {
+		t.Errorf("applied = %d, want %d", applied, latest)
+	}
+	assertVersion(t, m, latest)
+	schema := tables(t, m)
+	for _, want := range []string{"users", "diaries", "refresh_tokens", "streak_freezes", "tags", "diary_tags"} {
+		if !slices.Contains(schema, want) {
+			t.Errorf("table %s was not created: %v", want, schema)
+		}
+	}
+
+	// 適用済みなら何もしない
+	if applied, err := m.Up(); err != nil || applied != 0 {
+		t.Errorf("second up: applied = %d, error = %v", applied, err)
+	}
+
+	reverted, err := m.Down(latest)
+	if err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if reverted != latest {
+		t.Errorf("reverted = %d, want %d", reverted, latest)
+	}
+	assertVersion(t, m, 0)
+	if got := tables(t, m); len(got) != 0 {
+		t.Errorf("tables left after down: %v", got)
+	}
+
+	// down ファイルが up を元に戻せていれば、もう一度すべて適用できる
+	if applied, err := m.Up(); err != nil || applied != latest {
+		t.Fatalf("up again: applied = %d, error = %v", applied, err)
+	}
+	if got := tables(t, m); !slices.Equal(got, schema) {
+		t.Errorf("tables = %v, want %v", got, schema)
+	}
+}
+
+func TestDownSteps(t *testing.T) {
+	m := newTestMigrator(t)
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	if reverted, err := m.Down(1); err != nil || reverted != 1 {
+		t.Fatalf("down: reverted = %d, error = %v", reverted, err)
+	}
+	assertVersion(t, m, m.Latest()-1)
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(statuses) != m.Latest() {
+		t.Fatalf("statuses = %d, want %d", len(statuses), m.Latest())
+	}
+	for _, s := range statuses {
+		if want := s.Version < m.Latest(); s.Applied != want {
+			t.Errorf("%04d_%s: applied = %v, want %v", s.Version, s.Name, s.Applied, want)
+		}
+	}
+}
+
+func TestCheckSchemaTooNew(t *testing.T) {
+	m := newTestMigrator(t)
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if err := m.Check(); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	// 新しいバイナリで適用されたマイグレーション
+	newer := m.Latest() + 1
+	if _, err := m.db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", newer, "from_newer_binary", time.Now().UTC()); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	if err := m.Check(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("check: error = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := m.Up(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("up: error = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := m.Down(1); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("down: error = %v, want ErrSchemaTooNew", err)
+	}
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if last := statuses[len(statuses)-1]; last.Version != newer || last.Name != "(unknown)" || !last.Applied {
+		t.Errorf("last status = %+v", last)
+	}
+}
+
+func TestLowercaseTagNames(t *testing.T) {
+	m := newTestMigrator(t)
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	// 0009_lowercase_tag_names の前の状態に戻して、大文字と小文字だけが違うタグを作る
+	if _, err := m.Down(m.Latest() - 8); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	now := time.Now().UTC()
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT INTO users (id, username, email, password_hash) VALUES (?, ?, ?, ?)", []interface{}{"u1", "tester", "tester@example.com", ""}},
+		{"INSERT INTO diaries (id, user_id, date, rating, progress, wake_up_time, sleep_time) VALUES (?, ?, ?, 3, 'A', '07:00', '23:00')", []interface{}{"d1", "u1", "2026-01-01"}},
+		{"INSERT INTO diaries (id, user_id, date, rating, progress, wake_up_time, sleep_time) VALUES (?, ?, ?, 3, 'A', '07:00', '23:00')", []interface{}{"d2", "u1", "2026-01-02"}},
+		{"INSERT INTO tags (id, user_id, name, created_at) VALUES (?, ?, ?, ?)", []interface{}{"t1", "u1", "work", now}},
+		{"INSERT INTO tags (id, user_id, name, created_at) VALUES (?, ?, ?, ?)", []interface{}{"t2", "u1", "Work", now}},
+		{"INSERT INTO tags (id, user_id, name, created_at) VALUES (?, ?, ?, ?)", []interface{}{"t3", "u1", "GYM", now}},
+		{"INSERT INTO diary_tags (diary_id, tag_id) VALUES (?, ?)", []interface{}{"d1", "t1"}},
+		{"INSERT INTO diary_tags (diary_id, tag_id) VALUES (?, ?)", []interface{}{"d1", "t2"}},
+		{"INSERT INTO diary_tags (diary_id, tag_id) VALUES (?, ?)", []interface{}{"d2", "t2"}},
+		{"INSERT INTO diary_tags (diary_id, tag_id) VALUES (?, ?)", []interface{}{"d2", "t3"}},
+	}
+	for _, stmt := range statements {
+		if _, err := m.db.Exec(stmt.query, stmt.args...); err != nil {
+			t.Fatalf("%s: %v", stmt.query, err)
+		}
+	}
+
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	rows, err := m.db.Query("SELECT dt.diary_id, t.id, t.name FROM diary_tags dt JOIN tags t ON t.id = dt.tag_id ORDER BY dt.diary_id, t.name")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var diaryID, tagID, name string
+		if err := rows.Scan(&diaryID, &tagID, &name); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, diaryID+":"+tagID+":"+name)
+	}
+	want := []string{"d1:t1:work", "d2:t3:gym", "d2:t1:work"}
+	if !slices.Equal(got, want) {
+		t.Errorf("diary tags = %v, want %v", got, want)
+	}
+
+	var count int
+	if err := m.db.QueryRow("SELECT COUNT(*) FROM tags").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("tags = %d, want 2", count)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, driver := range []string{"mysql", "postgres", "sqlite"} {
+		t.Run(driver, func(t *testing.T) {
+			migrations, err := load(driver)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			for i, m := range migrations {
+				if m.Version != i+1 || len(splitStatements(m.Up)) == 0 || len(splitStatements(m.Down)) == 0 {
+					t.Errorf("migration %04d_%s is empty or out of order", m.Version, m.Name)
+				}
+			}
+		})
+	}
+
+	if _, err := load("oracle"); err == nil {
+		t.Error("load an unknown driver: no error")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "empty", script: "", want: nil},
+		{name: "single", script: "DROP TABLE tags;\n", want: []string{"DROP TABLE tags"}},
+		{
+			name:   "several statements",
+			script: "CREATE TABLE a (\n    id INTEGER\n);\n\nCREATE INDEX idx_a ON a(id);\nDROP TABLE b;",
+			want:   []string{"CREATE TABLE a (\n    id INTEGER\n)", "CREATE INDEX idx_a ON a(id)", "DROP TABLE b"},
+		},
+		{
+			name:   "comment lines",
+			script: "-- タグ\nCREATE TABLE a (id INTEGER);\n  -- インデックス\nCREATE INDEX idx_a ON a(id);\n",
+			want:   []string{"CREATE TABLE a (id INTEGER)", "CREATE INDEX idx_a ON a(id)"},
+		},
+		{name: "no trailing semicolon", script: "DROP TABLE a;\nDROP TABLE b\n", want: []string{"DROP TABLE a", "DROP TABLE b"}},
+		{name: "semicolon inside a line", script: "SELECT ';' AS x, 1;\n", want: []string{"SELECT ';' AS x, 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitStatements(tt.script)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
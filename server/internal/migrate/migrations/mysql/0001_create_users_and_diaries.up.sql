@@ -0,0 +1,26 @@
+-- 既存の initDB で作成済みのデータベースもそのまま取り込めるよう IF NOT EXISTS を付ける
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(36) PRIMARY KEY,
+    username VARCHAR(30) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS diaries (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    date DATE NOT NULL,
+    rating INT NOT NULL CHECK (rating >= 1 AND rating <= 5),
+    progress VARCHAR(1) NOT NULL CHECK (progress IN ('A', 'B', 'C')),
+    wake_up_time VARCHAR(5) NOT NULL,
+    sleep_time VARCHAR(5) NOT NULL,
+    memo TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_user_date (user_id, date),
+    INDEX idx_user_id (user_id),
+    INDEX idx_date (date),
+    INDEX idx_user_date (user_id, date)
+);
@@ -0,0 +1,6 @@
+DROP TABLE refresh_tokens;
+
+ALTER TABLE users
+    DROP INDEX unique_email,
+    DROP INDEX unique_username,
+    DROP COLUMN password_hash;
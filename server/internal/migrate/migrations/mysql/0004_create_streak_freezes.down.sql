@@ -0,0 +1 @@
+DROP TABLE streak_freezes;
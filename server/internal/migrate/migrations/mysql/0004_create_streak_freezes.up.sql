@@ -0,0 +1,8 @@
+-- 連続記録を途切れさせないために使ったフリーズ日
+CREATE TABLE streak_freezes (
+    user_id VARCHAR(36) NOT NULL,
+    date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, date),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
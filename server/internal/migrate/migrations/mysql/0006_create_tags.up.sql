@@ -0,0 +1,18 @@
+-- ユーザーが日記に付けるタグ（名前はユーザーごとに一意）
+CREATE TABLE tags (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_user_tag_name (user_id, name)
+);
+
+CREATE TABLE diary_tags (
+    diary_id VARCHAR(36) NOT NULL,
+    tag_id VARCHAR(36) NOT NULL,
+    PRIMARY KEY (diary_id, tag_id),
+    FOREIGN KEY (diary_id) REFERENCES diaries(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    INDEX idx_diary_tags_tag_id (tag_id)
+);
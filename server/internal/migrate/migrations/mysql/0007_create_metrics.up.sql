@@ -0,0 +1,25 @@
+-- ユーザーが定義する日記の記録項目（名前はユーザーごとに一意）
+CREATE TABLE metrics (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(30) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('number', 'boolean', 'scale', 'duration')),
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    min_value DOUBLE,
+    max_value DOUBLE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_user_metric_name (user_id, name)
+);
+
+-- 日記ごとの値（boolean は 1 / 0、duration は分）
+CREATE TABLE diary_metric_values (
+    diary_id VARCHAR(36) NOT NULL,
+    metric_id VARCHAR(36) NOT NULL,
+    value DOUBLE NOT NULL,
+    PRIMARY KEY (diary_id, metric_id),
+    FOREIGN KEY (diary_id) REFERENCES diaries(id) ON DELETE CASCADE,
+    FOREIGN KEY (metric_id) REFERENCES metrics(id) ON DELETE CASCADE,
+    INDEX idx_diary_metric_values_metric_id (metric_id)
+);
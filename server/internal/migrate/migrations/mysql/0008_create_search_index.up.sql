@@ -0,0 +1,24 @@
+-- メモの全文検索用の索引。日記ごとに1行（メモが空でも作成し、索引済みの印にする）。
+-- body は正規化したメモで、検索語が語順どおりに含まれるかを確かめるために使う（バイナリ照合順序で比べる）
+CREATE TABLE diary_search_docs (
+    diary_id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    length INT NOT NULL,
+    body MEDIUMTEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    FOREIGN KEY (diary_id) REFERENCES diaries(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_diary_search_docs_user_id (user_id)
+);
+
+-- 索引語（英数字は単語、日本語は1文字と2文字の組）と出現回数。
+-- 濁点の有無などを区別するためバイナリ照合順序にする
+CREATE TABLE diary_search_terms (
+    diary_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    term VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    tf INT NOT NULL,
+    PRIMARY KEY (diary_id, term),
+    FOREIGN KEY (diary_id) REFERENCES diaries(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_diary_search_terms_user_term (user_id, term)
+);
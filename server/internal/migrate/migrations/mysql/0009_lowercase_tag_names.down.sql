@@ -0,0 +1,2 @@
+-- 元の大文字と小文字は復元できないため、名前はそのままにする
+SELECT 1;
@@ -0,0 +1,3 @@
+-- タグ名は大文字と小文字を区別しないため小文字にそろえる。
+-- MySQL の照合順序では大文字と小文字だけが違うタグは作成できないため、統合は不要
+UPDATE tags SET name = LOWER(name);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username VARCHAR(30) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS diaries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    rating INTEGER CHECK (rating >= 1 AND rating <= 5) NOT NULL,
+    progress VARCHAR(1) CHECK (progress IN ('A', 'B', 'C')) NOT NULL,
+    wake_up_time TIME NOT NULL,
+    sleep_time TIME NOT NULL,
+    memo TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_user_date UNIQUE (user_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_diaries_user_id ON diaries(user_id);
+CREATE INDEX IF NOT EXISTS idx_diaries_date ON diaries(date);
+CREATE INDEX IF NOT EXISTS idx_diaries_user_date ON diaries(user_id, date);
@@ -0,0 +1,6 @@
+DROP TABLE refresh_tokens;
+
+ALTER TABLE users
+    DROP CONSTRAINT unique_email,
+    DROP CONSTRAINT unique_username,
+    DROP COLUMN password_hash;
@@ -0,0 +1,7 @@
+-- 連続記録を途切れさせないために使ったフリーズ日
+CREATE TABLE streak_freezes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, date)
+);
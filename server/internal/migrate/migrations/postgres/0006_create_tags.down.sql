@@ -0,0 +1,2 @@
+DROP TABLE diary_tags;
+DROP TABLE tags;
@@ -0,0 +1,16 @@
+-- ユーザーが日記に付けるタグ（名前はユーザーごとに一意）
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_user_tag_name UNIQUE (user_id, name)
+);
+
+CREATE TABLE diary_tags (
+    diary_id UUID NOT NULL REFERENCES diaries(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (diary_id, tag_id)
+);
+
+CREATE INDEX idx_diary_tags_tag_id ON diary_tags(tag_id);
@@ -0,0 +1,2 @@
+DROP TABLE diary_metric_values;
+DROP TABLE metrics;
@@ -0,0 +1,20 @@
+-- メモの全文検索用の索引。日記ごとに1行（メモが空でも作成し、索引済みの印にする）。
+-- body は正規化したメモで、検索語が語順どおりに含まれるかを確かめるために使う
+CREATE TABLE diary_search_docs (
+    diary_id UUID PRIMARY KEY REFERENCES diaries(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    length INTEGER NOT NULL,
+    body TEXT NOT NULL
+);
+
+-- 索引語（英数字は単語、日本語は1文字と2文字の組）と出現回数
+CREATE TABLE diary_search_terms (
+    diary_id UUID NOT NULL REFERENCES diaries(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    term VARCHAR(64) NOT NULL,
+    tf INTEGER NOT NULL,
+    PRIMARY KEY (diary_id, term)
+);
+
+CREATE INDEX idx_diary_search_docs_user_id ON diary_search_docs(user_id);
+CREATE INDEX idx_diary_search_terms_user_term ON diary_search_terms(user_id, term);
@@ -0,0 +1,12 @@
+-- タグ名は大文字と小文字を区別しないため小文字にそろえる。
+-- 大文字と小文字だけが違うタグは、ID の最も小さいタグに統合する
+INSERT INTO diary_tags (diary_id, tag_id)
+SELECT dt.diary_id, (SELECT CAST(MIN(CAST(k.id AS TEXT)) AS UUID) FROM tags k WHERE k.user_id = t.user_id AND LOWER(k.name) = LOWER(t.name))
+FROM diary_tags dt
+JOIN tags t ON t.id = dt.tag_id
+ON CONFLICT DO NOTHING;
+
+DELETE FROM tags
+WHERE CAST(id AS TEXT) <> (SELECT MIN(CAST(k.id AS TEXT)) FROM tags k WHERE k.user_id = tags.user_id AND LOWER(k.name) = LOWER(tags.name));
+
+UPDATE tags SET name = LOWER(name);
@@ -0,0 +1,2 @@
+DROP TABLE diaries;
+DROP TABLE users;
@@ -0,0 +1,5 @@
+DROP TABLE refresh_tokens;
+
+DROP INDEX unique_email;
+DROP INDEX unique_username;
+ALTER TABLE users DROP COLUMN password_hash;
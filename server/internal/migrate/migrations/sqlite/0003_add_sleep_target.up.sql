@@ -0,0 +1,2 @@
+-- 睡眠負債の計算に使う1晩の目標睡眠時間（分）
+ALTER TABLE users ADD COLUMN sleep_target_minutes INTEGER NOT NULL DEFAULT 480;
@@ -0,0 +1,2 @@
+-- 「今日」や週・月・年の区切りに使う IANA タイムゾーン名（空文字の場合はサーバーのタイムゾーン）
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,23 @@
+-- ユーザーが定義する日記の記録項目（名前はユーザーごとに一意）
+CREATE TABLE metrics (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT CHECK (type IN ('number', 'boolean', 'scale', 'duration')) NOT NULL,
+    unit TEXT NOT NULL DEFAULT '',
+    min_value REAL,
+    max_value REAL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_user_metric_name UNIQUE (user_id, name)
+);
+
+-- 日記ごとの値（boolean は 1 / 0、duration は分）
+CREATE TABLE diary_metric_values (
+    diary_id TEXT NOT NULL REFERENCES diaries(id) ON DELETE CASCADE,
+    metric_id TEXT NOT NULL REFERENCES metrics(id) ON DELETE CASCADE,
+    value REAL NOT NULL,
+    PRIMARY KEY (diary_id, metric_id)
+);
+
+CREATE INDEX idx_diary_metric_values_metric_id ON diary_metric_values(metric_id);
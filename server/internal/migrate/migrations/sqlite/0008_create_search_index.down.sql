@@ -0,0 +1,2 @@
+DROP TABLE diary_search_terms;
+DROP TABLE diary_search_docs;
@@ -0,0 +1,11 @@
+-- タグ名は大文字と小文字を区別しないため小文字にそろえる。
+-- 大文字と小文字だけが違うタグは、ID の最も小さいタグに統合する
+INSERT OR IGNORE INTO diary_tags (diary_id, tag_id)
+SELECT dt.diary_id, (SELECT MIN(k.id) FROM tags k WHERE k.user_id = t.user_id AND LOWER(k.name) = LOWER(t.name))
+FROM diary_tags dt
+JOIN tags t ON t.id = dt.tag_id;
+
+DELETE FROM tags
+WHERE id <> (SELECT MIN(k.id) FROM tags k WHERE k.user_id = tags.user_id AND LOWER(k.name) = LOWER(tags.name));
+
+UPDATE tags SET name = LOWER(name);
@@ -13,28 +13,79 @@ type Diary struct {
 	Memo        string    `json:"memo"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// SleepDurationMinutes は前日の就寝時刻から当日の起床時刻までの睡眠時間（前日の記録がない場合は nil）
+	SleepDurationMinutes *int `json:"sleep_duration_minutes"`
+	// Tags はタグ名（名前順）
+	Tags []string `json:"tags"`
+	// Metrics は記録項目の ID ごとの値（boolean は true / false、それ以外は数値）
+	Metrics map[string]interface{} `json:"metrics"`
 }
 
 type CreateDiaryRequest struct {
-	Date       string `json:"date" binding:"required,datetime=2006-01-02"`
+	Date       string `json:"date" binding:"required,diarydate"`
 	Rating     int    `json:"rating" binding:"required,min=1,max=5"`
 	Progress   string `json:"progress" binding:"required,oneof=A B C"`
-	WakeUpTime string `json:"wake_up_time" binding:"required"`
-	SleepTime  string `json:"sleep_time" binding:"required"`
+	WakeUpTime string `json:"wake_up_time" binding:"required,hhmm"`
+	SleepTime  string `json:"sleep_time" binding:"required,hhmm"`
 	Memo       string `json:"memo"`
+	// Tags は付けるタグ名。存在しないタグは作成する
+	Tags []string `json:"tags" binding:"omitempty,max=10,dive,tagname"`
+	// Metrics は記録項目の ID ごとの値。記録項目の定義に合わせて検証する
+	Metrics map[string]interface{} `json:"metrics"`
 }
 
 type UpdateDiaryRequest struct {
 	Rating     *int    `json:"rating" binding:"omitempty,min=1,max=5"`
 	Progress   *string `json:"progress" binding:"omitempty,oneof=A B C"`
-	WakeUpTime *string `json:"wake_up_time"`
-	SleepTime  *string `json:"sleep_time"`
+	WakeUpTime *string `json:"wake_up_time" binding:"omitempty,hhmm"`
+	SleepTime  *string `json:"sleep_time" binding:"omitempty,hhmm"`
 	Memo       *string `json:"memo"`
+	// Tags を指定した場合はタグを置き換える（空配列ですべて外す）
+	Tags *[]string `json:"tags" binding:"omitempty,max=10,dive,tagname"`
+	// Metrics は指定した記録項目の値だけを更新する（null を指定した項目は値を消す）
+	Metrics map[string]interface{} `json:"metrics"`
+}
+
+// ListDiariesQuery は日記一覧のクエリパラメータ
+type ListDiariesQuery struct {
+	StartDate string `form:"start_date" json:"start_date" binding:"omitempty,diarydate"`
+	EndDate   string `form:"end_date" json:"end_date" binding:"omitempty,diarydate"`
+	Limit     int    `form:"limit" json:"limit" binding:"omitempty,min=1"`
+	Offset    int    `form:"offset" json:"offset" binding:"omitempty,min=0"`
+	// Cursor を指定した場合（空文字を含む）はカーソル方式でページングする
+	Cursor *string `form:"cursor" json:"cursor"`
+	// Tags はカンマ区切りのタグ名。TagMatch が all（既定）の場合はすべて、any の場合はいずれかが付いた日記に絞る
+	Tags     string `form:"tags" json:"tags"`
+	TagMatch string `form:"tag_match" json:"tag_match" binding:"omitempty,oneof=all any"`
+	// Filter は絞り込み式（例: rating>=4 AND progress:A AND sleep_time<23:00）
+	Filter string `form:"filter" json:"filter" binding:"max=500"`
+	// Sort / Order は並び順（既定は date の desc）
+	Sort  string `form:"sort" json:"sort" binding:"omitempty,oneof=date rating updated_at"`
+	Order string `form:"order" json:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+type Pagination struct {
+	Total   int  `json:"total"` // 条件に一致する日記の件数
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"has_more"`
+	// Next は次のページの URL（ない場合は null）
+	Next *string `json:"next"`
+	// NextCursor はカーソル方式の場合の次のページのカーソル
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+type DiaryList struct {
+	Diaries    []Diary    `json:"diaries"`
+	Pagination Pagination `json:"pagination"`
 }
 
 type CalendarEntry struct {
-	Date   string `json:"date"`
-	Rating int    `json:"rating"`
+	Date     string `json:"date"`
+	Rating   int    `json:"rating"`
+	Progress string `json:"progress"`
+	HasMemo  bool   `json:"has_memo"`
 }
 
 type CalendarResponse struct {
@@ -60,17 +111,55 @@ type Statistics struct {
 	ProgressDistribution map[string]int    `json:"progress_distribution"`
 	AverageWakeUpTime    string            `json:"average_wake_up_time"`
 	AverageSleepTime     string            `json:"average_sleep_time"`
+	WakeUpTimeStdDev     float64           `json:"wake_up_time_std_dev"` // 分
+	SleepTimeStdDev      float64           `json:"sleep_time_std_dev"`   // 分
 	LongestStreak        int               `json:"longest_streak"`
+
+	// TagStats はタグごとの記録数と平均評価（記録数の多い順）
+	TagStats []TagStat `json:"tag_stats"`
+	// MetricStats は記録項目ごとの集計（記録項目の作成順）
+	MetricStats []MetricStat `json:"metric_stats"`
+	// Comparison は compare=previous を指定した場合のみ設定される
+	Comparison *StatisticsComparison `json:"comparison,omitempty"`
+}
+
+// StatisticsComparison は直前の期間の統計と、その期間からの増減
+type StatisticsComparison struct {
+	Previous *Statistics     `json:"previous"`
+	Delta    StatisticsDelta `json:"delta"`
+}
+
+// StatisticsDelta は今期 - 前期の値
+type StatisticsDelta struct {
+	TotalEntries         int            `json:"total_entries"`
+	AverageRating        *float64       `json:"average_rating"` // どちらかの期間に記録がない場合は null
+	ProgressDistribution map[string]int `json:"progress_distribution"`
+	LongestStreak        int            `json:"longest_streak"`
 }
 
 type TrendData struct {
 	PeriodDays int           `json:"period_days"`
 	Data       []TrendEntry  `json:"data"`
+
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date"`
+	Bucket        string `json:"bucket"`                   // day / week / month
+	RollingWindow int    `json:"rolling_window,omitempty"` // 7 / 30（bucket=day のみ）
 }
 
+// TrendEntry は1日または1区間（週・月）の値。記録がない場合は各値が null になる。
 type TrendEntry struct {
-	Date   string `json:"date"`
-	Rating int    `json:"rating"`
+	Date   string   `json:"date"`   // bucket=week / month の場合は区間の初日
+	Rating *float64 `json:"rating"` // 区間の場合は平均
+
+	Entries              int      `json:"entries"`                // 区間内の日記の件数
+	Progress             *string  `json:"progress"`               // bucket=day のみ
+	ProgressScore        *float64 `json:"progress_score"`         // A=3, B=2, C=1 の平均
+	SleepDurationMinutes *float64 `json:"sleep_duration_minutes"` // 平均
+	RollingAverage       *float64 `json:"rolling_average"`        // 直近 RollingWindow 日の評価の平均
+
+	// Metrics は記録項目の ID ごとの平均（boolean は true の割合、値がない場合は null）。記録項目がない場合は省略
+	Metrics map[string]*float64 `json:"metrics,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -80,5 +169,6 @@ type ErrorResponse struct {
 type ErrorDetail struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	// Details はフィールド名（JSON名）ごとのエラー内容
+	Details map[string]string `json:"details,omitempty"`
 }
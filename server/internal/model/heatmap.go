@@ -0,0 +1,34 @@
+package model
+
+// HeatmapCell は年間ヒートマップの1日分
+type HeatmapCell struct {
+	Date     string `json:"date"`
+	Weekday  int    `json:"weekday"` // 0=日曜〜6=土曜
+	Recorded bool   `json:"recorded"`
+	// 記録がない日は null
+	Rating               *int    `json:"rating"`
+	Progress             *string `json:"progress"`
+	HasMemo              bool    `json:"has_memo"`
+	SleepDurationMinutes *int    `json:"sleep_duration_minutes"`
+	// Intensity は metric から求めた濃さ（0=値なし〜MaxIntensity）
+	Intensity int `json:"intensity"`
+	// Value は metric に記録項目の ID を指定した場合のその日の値（boolean は 1 / 0）
+	Value *float64 `json:"value,omitempty"`
+}
+
+type HeatmapMonth struct {
+	Month int `json:"month"`
+	CalendarSummary
+}
+
+// YearHeatmap は1年分（365 / 366 日）のヒートマップ
+type YearHeatmap struct {
+	Year         int             `json:"year"`
+	Metric       string          `json:"metric"` // rating / progress / sleep / 記録項目の ID
+	StartDate    string          `json:"start_date"`
+	EndDate      string          `json:"end_date"`
+	MaxIntensity int             `json:"max_intensity"`
+	Cells        []HeatmapCell   `json:"cells"`
+	Months       []HeatmapMonth  `json:"months"` // 1月〜12月の順
+	Summary      CalendarSummary `json:"summary"`
+}
@@ -0,0 +1,44 @@
+package model
+
+// Correlation は2つの指標の相関
+type Correlation struct {
+	X          string   `json:"x"`
+	Y          string   `json:"y"`
+	SampleSize int      `json:"sample_size"`
+	Pearson    *float64 `json:"pearson"`  // 値が一定などで計算できない場合は null
+	Spearman   *float64 `json:"spearman"` // 順位相関（評価・進捗は順序尺度のためこちらを主に使う）
+	// CILow / CIHigh は Spearman の 95% 信頼区間（Fisher の z 変換による近似）
+	CILow  *float64 `json:"ci_low"`
+	CIHigh *float64 `json:"ci_high"`
+	// Strength は none / weak / moderate / strong
+	Strength string `json:"strength"`
+	// Significant は標本数が十分で、信頼区間が 0 を含まない場合に true
+	Significant bool `json:"significant"`
+}
+
+type ComparisonGroup struct {
+	Label         string   `json:"label"`
+	SampleSize    int      `json:"sample_size"`
+	AverageRating *float64 `json:"average_rating"`
+}
+
+// GroupComparison は条件で分けた2グループの評価の比較
+type GroupComparison struct {
+	Name       string          `json:"name"`
+	A          ComparisonGroup `json:"a"`
+	B          ComparisonGroup `json:"b"`
+	Difference *float64        `json:"difference"` // A - B
+	// Significant は両グループの標本が十分で、差が標準誤差に比べて大きい場合に true
+	Significant bool `json:"significant"`
+}
+
+type Insights struct {
+	Period        string            `json:"period"`
+	PeriodStart   string            `json:"period_start"`
+	PeriodEnd     string            `json:"period_end"`
+	TotalEntries  int               `json:"total_entries"`
+	MinSampleSize int               `json:"min_sample_size"`
+	Correlations  []Correlation     `json:"correlations"`
+	Comparisons   []GroupComparison `json:"comparisons"`
+	Findings      []string          `json:"findings"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// 記録項目の種類
+const (
+	MetricTypeNumber   = "number"   // 任意の数値
+	MetricTypeBoolean  = "boolean"  // true / false
+	MetricTypeScale    = "scale"    // Min〜Max の整数（段階評価）
+	MetricTypeDuration = "duration" // 分（0以上の整数）
+)
+
+// Metric はユーザーが定義する日記の記録項目（名前はユーザーごとに一意）
+type Metric struct {
+	ID     string `json:"id"`
+	UserID string `json:"-"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Unit   string `json:"unit"`
+	// Min / Max は値の範囲（指定しない場合は null）。boolean では使わない
+	Min       *float64  `json:"min"`
+	Max       *float64  `json:"max"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateMetricRequest struct {
+	Name string   `json:"name" binding:"required,max=30"`
+	Type string   `json:"type" binding:"required,oneof=number boolean scale duration"`
+	Unit string   `json:"unit" binding:"max=20"`
+	Min  *float64 `json:"min"`
+	Max  *float64 `json:"max"`
+}
+
+// UpdateMetricRequest は種類以外の項目を置き換える（種類は変更できない）
+type UpdateMetricRequest struct {
+	Name string   `json:"name" binding:"required,max=30"`
+	Unit string   `json:"unit" binding:"max=20"`
+	Min  *float64 `json:"min"`
+	Max  *float64 `json:"max"`
+}
+
+// MetricStat は記録項目ごとの期間内の集計
+type MetricStat struct {
+	MetricID     string `json:"metric_id"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Unit         string `json:"unit"`
+	TotalEntries int    `json:"total_entries"` // 値を記録した日数
+	// Average は平均（boolean の場合は true の割合）。記録がない場合は null
+	Average *float64 `json:"average"`
+	// Sum は合計（boolean の場合は true の日数）
+	Sum float64  `json:"sum"`
+	Min *float64 `json:"min"` // boolean の場合は null
+	Max *float64 `json:"max"` // boolean の場合は null
+}
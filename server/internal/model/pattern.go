@@ -0,0 +1,23 @@
+package model
+
+// PatternGroup は曜日・月などでまとめた日記の集計
+type PatternGroup struct {
+	Key                  string         `json:"key"`
+	TotalEntries         int            `json:"total_entries"`
+	AverageRating        *float64       `json:"average_rating"` // 記録がない場合は null
+	ProgressDistribution map[string]int `json:"progress_distribution"`
+	AverageWakeUpTime    string         `json:"average_wake_up_time"`
+	AverageSleepTime     string         `json:"average_sleep_time"`
+}
+
+type PatternAnalysis struct {
+	Period      string `json:"period"`
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	// ByWeekday は月曜〜日曜の順
+	ByWeekday []PatternGroup `json:"by_weekday"`
+	// ByMonth は1月〜12月の順（年をまたいで同じ月をまとめる）
+	ByMonth []PatternGroup `json:"by_month"`
+	// WeekdayVsWeekend は平日（月〜金）と週末（土日）
+	WeekdayVsWeekend []PatternGroup `json:"weekday_vs_weekend"`
+}
@@ -0,0 +1,45 @@
+package model
+
+// ReportMonth は年間レポートの月ごとの集計
+type ReportMonth struct {
+	Month         int      `json:"month"` // 1〜12
+	TotalEntries  int      `json:"total_entries"`
+	AverageRating *float64 `json:"average_rating"` // 記録がない場合は null
+	// AverageSleepDurationMinutes は睡眠時間を求められた夜の平均（ない場合は null）
+	AverageSleepDurationMinutes *float64 `json:"average_sleep_duration_minutes"`
+}
+
+// ReportDay は評価の高かった日
+type ReportDay struct {
+	Date     string `json:"date"`
+	Rating   int    `json:"rating"`
+	Progress string `json:"progress"`
+	// MemoExcerpt はメモの先頭 memoExcerptLength 文字（省略した場合は末尾に "…"）
+	MemoExcerpt string `json:"memo_excerpt"`
+}
+
+type ReportSleep struct {
+	RecordedNights         int      `json:"recorded_nights"`
+	AverageDurationMinutes *float64 `json:"average_duration_minutes"` // 記録がない場合は null
+	AverageWakeUpTime      string   `json:"average_wake_up_time"`
+	AverageSleepTime       string   `json:"average_sleep_time"`
+	// ShortestMonth / LongestMonth は平均睡眠時間が最も短い・長い月（記録がない場合は null）
+	ShortestMonth *int `json:"shortest_month"`
+	LongestMonth  *int `json:"longest_month"`
+}
+
+// YearReport は1年分の振り返り
+type YearReport struct {
+	Year               int             `json:"year"`
+	PeriodStart        string          `json:"period_start"`
+	PeriodEnd          string          `json:"period_end"`
+	Summary            *Statistics     `json:"summary"`
+	Months             []ReportMonth   `json:"months"`      // 1月〜12月の順
+	BestMonth          *ReportMonth    `json:"best_month"`  // 平均評価が最も高い月（記録がない場合は null）
+	WorstMonth         *ReportMonth    `json:"worst_month"` // 平均評価が最も低い月（記録がない場合は null）
+	LongestStreak      *Streak         `json:"longest_streak"`
+	MostCommonProgress string          `json:"most_common_progress"` // 記録がない場合は空文字
+	Heatmap            []CalendarEntry `json:"heatmap"`              // 記録した日の評価
+	Sleep              ReportSleep     `json:"sleep"`
+	TopDays            []ReportDay     `json:"top_days"` // 評価の高い順
+}
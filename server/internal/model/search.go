@@ -0,0 +1,32 @@
+package model
+
+// SearchDiariesQuery はメモの全文検索のクエリパラメータ
+type SearchDiariesQuery struct {
+	// Q は検索語。空白で区切った語をすべて含む日記を返す
+	Q         string `form:"q" json:"q" binding:"required,max=100"`
+	StartDate string `form:"start_date" json:"start_date" binding:"omitempty,diarydate"`
+	EndDate   string `form:"end_date" json:"end_date" binding:"omitempty,diarydate"`
+	MinRating int    `form:"min_rating" json:"min_rating" binding:"omitempty,min=1,max=5"`
+	MaxRating int    `form:"max_rating" json:"max_rating" binding:"omitempty,min=1,max=5"`
+	Limit     int    `form:"limit" json:"limit" binding:"omitempty,min=1"`
+	Offset    int    `form:"offset" json:"offset" binding:"omitempty,min=0"`
+}
+
+// SnippetPart はメモの抜粋の一部。Highlight が true の部分が検索語に一致する
+type SnippetPart struct {
+	Text      string `json:"text"`
+	Highlight bool   `json:"highlight"`
+}
+
+type SearchHit struct {
+	Diary   Diary         `json:"diary"`
+	Score   float64       `json:"score"` // 大きいほど関連が高い
+	Snippet []SnippetPart `json:"snippet"`
+}
+
+// SearchResult は検索結果（関連の高い順）
+type SearchResult struct {
+	Query      string      `json:"query"`
+	Results    []SearchHit `json:"results"`
+	Pagination Pagination  `json:"pagination"`
+}
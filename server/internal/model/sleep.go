@@ -0,0 +1,24 @@
+package model
+
+// SleepNight は1晩の睡眠（Date は起床した日）
+type SleepNight struct {
+	Date            string `json:"date"`
+	SleepTime       string `json:"sleep_time"` // 前日の就寝時刻
+	WakeUpTime      string `json:"wake_up_time"`
+	DurationMinutes int    `json:"duration_minutes"`
+	// DebtMinutes はこの日までの直近 DebtWindowDays 日の睡眠負債
+	DebtMinutes int `json:"debt_minutes"`
+}
+
+type SleepStatistics struct {
+	PeriodDays             int          `json:"period_days"`
+	PeriodStart            string       `json:"period_start"`
+	PeriodEnd              string       `json:"period_end"`
+	TargetMinutes          int          `json:"target_minutes"`
+	RecordedNights         int          `json:"recorded_nights"`
+	AverageDurationMinutes float64      `json:"average_duration_minutes"`
+	DebtWindowDays         int          `json:"debt_window_days"`
+	SleepDebtMinutes       int          `json:"sleep_debt_minutes"` // 期間末日時点の睡眠負債
+	Nights                 []SleepNight `json:"nights"`
+	ShortestNights         []SleepNight `json:"shortest_nights"`
+}
@@ -0,0 +1,29 @@
+package model
+
+// Streak は連続して記録した期間（フリーズ日で補った日を含む）
+type Streak struct {
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Length     int    `json:"length"`      // 記録した日数
+	FrozenDays int    `json:"frozen_days"` // フリーズで補った日数
+}
+
+type StreakStatus struct {
+	Today         string `json:"today"`
+	CurrentStreak int    `json:"current_streak"`
+	LongestStreak int    `json:"longest_streak"`
+	// Current は継続中の連続記録（途切れている場合は nil）
+	Current *Streak `json:"current"`
+	// AtRisk は連続記録が継続中だが今日の記録がまだない状態
+	AtRisk        bool `json:"at_risk"`
+	RecordedToday bool `json:"recorded_today"`
+
+	FreezesPerMonth  int      `json:"freezes_per_month"`
+	FreezesAvailable int      `json:"freezes_available"` // 今月の残り
+	Freezes          []string `json:"freezes"`
+	History          []Streak `json:"history"` // 新しい順
+}
+
+type CreateStreakFreezeRequest struct {
+	Date string `json:"date" binding:"required,diarydate"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Tag はユーザーが日記に付けるタグ（名前はユーザーごとに一意）
+type Tag struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	DiaryCount int       `json:"diary_count"` // このタグが付いた日記の数
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,tagname"`
+}
+
+type MergeTagRequest struct {
+	// TargetID は統合先のタグ
+	TargetID string `json:"target_id" binding:"required"`
+}
+
+// TagStat はタグごとの期間内の集計
+type TagStat struct {
+	Name          string  `json:"name"`
+	TotalEntries  int     `json:"total_entries"`
+	AverageRating float64 `json:"average_rating"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// DefaultSleepTargetMinutes は目標睡眠時間の初期値（8時間）
+const DefaultSleepTargetMinutes = 480
+
+// UserSettings はユーザーごとの設定
+type UserSettings struct {
+	SleepTargetMinutes int `json:"sleep_target_minutes"`
+	// Timezone は IANA タイムゾーン名（例: Asia/Tokyo）。空文字の場合はサーバーのタイムゾーン
+	Timezone string `json:"timezone"`
+}
+
+type UpdateSettingsRequest struct {
+	SleepTargetMinutes *int `json:"sleep_target_minutes" binding:"omitempty,min=60,max=960"`
+	// 空文字を指定すると未設定に戻す
+	Timezone *string `json:"timezone" binding:"omitempty,tzname"`
+}
+
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=30"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type AuthResponse struct {
+	User  *User         `json:"user"`
+	Token TokenResponse `json:"token"`
+}
+
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string
+	UserAgent string
+	IPAddress string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
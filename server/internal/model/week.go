@@ -0,0 +1,30 @@
+package model
+
+// WeekDay は週表示の1日分
+type WeekDay struct {
+	Date    string `json:"date"`
+	Weekday int    `json:"weekday"` // 0=日曜〜6=土曜
+	// Diary はその日の日記（記録がない場合は null）
+	Diary *Diary `json:"diary"`
+}
+
+type WeekSummary struct {
+	TotalDays            int            `json:"total_days"`
+	RecordedDays         int            `json:"recorded_days"`
+	AverageRating        *float64       `json:"average_rating"` // 記録がない場合は null
+	ProgressDistribution map[string]int `json:"progress_distribution"`
+	AverageWakeUpTime    string         `json:"average_wake_up_time"`
+	AverageSleepTime     string         `json:"average_sleep_time"`
+	// AverageSleepDurationMinutes は睡眠時間を求められた夜の平均（ない場合は null）
+	AverageSleepDurationMinutes *float64 `json:"average_sleep_duration_minutes"`
+}
+
+// WeekView は ISO 8601 の週（月曜始まり）の7日分
+type WeekView struct {
+	Year      int         `json:"year"` // ISO 週の年（1月初めや12月末は暦の年と異なることがある）
+	Week      int         `json:"week"`
+	StartDate string      `json:"start_date"`
+	EndDate   string      `json:"end_date"`
+	Days      []WeekDay   `json:"days"`
+	Summary   WeekSummary `json:"summary"`
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialect は SQL バックエンドごとの差異をまとめたもの
+type dialect struct {
+	name string
+	// rebind は ? プレースホルダーをバックエンドの形式に変換する（nil の場合は変換しない）
+	rebind func(query string) string
+	// insertIgnore は INSERT 文を一意制約違反を無視する形に変換する
+	insertIgnore func(query string) string
+	// isUniqueViolation はドライバーのエラーが一意制約違反かどうかを判定する
+	isUniqueViolation func(err error) bool
+}
+
+// queryer は *sql.DB と *sql.Tx に共通するクエリの実行メソッド
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// sqlDB はクエリのプレースホルダーを dialect に合わせて変換してから実行する。
+// トランザクション内（tx が nil でない場合）はそのトランザクションで実行する。
+type sqlDB struct {
+	db *sql.DB
+	tx *sql.Tx
+	d  dialect
+}
+
+func (db *sqlDB) bind(query string) string {
+	if db.d.rebind == nil {
+		return query
+	}
+	return db.d.rebind(query)
+}
+
+func (db *sqlDB) conn() queryer {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.db
+}
+
+func (db *sqlDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.conn().Exec(db.bind(query), args...)
+}
+
+func (db *sqlDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.conn().Query(db.bind(query), args...)
+}
+
+func (db *sqlDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.conn().QueryRow(db.bind(query), args...)
+}
+
+// inTx は fn を1つのトランザクションで実行し、fn がエラーを返した場合はロールバックする。
+// 既にトランザクション内の場合は、外側のトランザクションの一部として fn を実行する。
+func (db *sqlDB) inTx(fn func(tx *sqlDB) error) error {
+	if db.tx != nil {
+		return fn(db)
+	}
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&sqlDB{db: db.db, tx: tx, d: db.d}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Rebind は ? プレースホルダーを driver (mysql / postgres / sqlite) の形式に変換する。
+// リポジトリ以外（マイグレーション）からも同じ変換を使う。
+func Rebind(driver, query string) string {
+	if driver == postgresDialect.name {
+		return postgresDialect.rebind(query)
+	}
+	return query
+}
+
+// rebindDollar は ? を $1, $2, ... に置き換える（PostgreSQL 用）
+func rebindDollar(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func openSQL(driver, dsn string, d dialect) (*Store, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if d.name == "sqlite" {
+		// SQLite は書き込みロックが1つのため、接続を1本に絞る
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	sdb := &sqlDB{db: db, d: d}
+	repos := sqlRepos(sdb)
+	return &Store{
+		Diaries:       repos.Diaries,
+		Users:         &sqlUserRepository{db: sdb, d: d},
+		StreakFreezes: &sqlStreakFreezeRepository{db: sdb, d: d},
+		Tags:          repos.Tags,
+		Metrics:       repos.Metrics,
+		SearchIndex:   repos.SearchIndex,
+		RefreshTokens: &sqlRefreshTokenRepository{db: sdb, d: d},
+		db:            db,
+		driver:        d.name,
+		withTx: func(fn func(Repos) error) error {
+			return sdb.inTx(func(tx *sqlDB) error {
+				return fn(sqlRepos(tx))
+			})
+		},
+	}, nil
+}
+
+// sqlRepos は db（トランザクション内の場合はそのトランザクション）で実行する日記関連のリポジトリを返す
+func sqlRepos(db *sqlDB) Repos {
+	return Repos{
+		Diaries:     &sqlDiaryRepository{db: db, d: db.d},
+		Tags:        &sqlTagRepository{db: db, d: db.d},
+		Metrics:     &sqlMetricRepository{db: db, d: db.d},
+		SearchIndex: &sqlSearchIndexRepository{db: db, d: db.d},
+	}
+}
+
+// dateValue は DATE 列を YYYY-MM-DD 形式の文字列として読み取る。
+// MySQL (parseTime=true) や SQLite は time.Time を返すことがあるため、その差異を吸収する。
+type dateValue struct {
+	s *string
+}
+
+func (v dateValue) Scan(src interface{}) error {
+	switch t := src.(type) {
+	case time.Time:
+		*v.s = t.Format("2006-01-02")
+	case string:
+		*v.s = truncateDate(t)
+	case []byte:
+		*v.s = truncateDate(string(t))
+	default:
+		return fmt.Errorf("cannot scan %T into date", src)
+	}
+	return nil
+}
+
+func truncateDate(s string) string {
+	if len(s) > 10 {
+		return s[:10]
+	}
+	return s
+}
+
+// clockValue は時刻 (HH:MM) 列を読み取る。PostgreSQL の TIME 型は
+// time.Time や "07:00:00" として返されるため、HH:MM 形式にそろえる。
+type clockValue struct {
+	s *string
+}
+
+func (v clockValue) Scan(src interface{}) error {
+	switch t := src.(type) {
+	case time.Time:
+		*v.s = t.Format("15:04")
+	case string:
+		*v.s = truncateClock(t)
+	case []byte:
+		*v.s = truncateClock(string(t))
+	default:
+		return fmt.Errorf("cannot scan %T into clock time", src)
+	}
+	return nil
+}
+
+func truncateClock(s string) string {
+	if len(s) > 5 {
+		return s[:5]
+	}
+	return s
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// timeValue は TIMESTAMP 列を読み取る。集計関数の結果など、
+// ドライバーが文字列で返す場合も time.Time に変換する。NULL はゼロ値になる。
+type timeValue struct {
+	t *time.Time
+}
+
+func (v timeValue) Scan(src interface{}) error {
+	switch t := src.(type) {
+	case nil:
+		*v.t = time.Time{}
+		return nil
+	case time.Time:
+		*v.t = t
+		return nil
+	case []byte:
+		return v.parse(string(t))
+	case string:
+		return v.parse(t)
+	default:
+		return fmt.Errorf("cannot scan %T into time", src)
+	}
+}
+
+func (v timeValue) parse(s string) error {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			*v.t = t
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as time", s)
+}
+
+// nullTimeValue は NULL を許容する TIMESTAMP 列を *time.Time として読み取る
+type nullTimeValue struct {
+	t **time.Time
+}
+
+func (v nullTimeValue) Scan(src interface{}) error {
+	if src == nil {
+		*v.t = nil
+		return nil
+	}
+	var t time.Time
+	if err := (timeValue{t: &t}).Scan(src); err != nil {
+		return err
+	}
+	*v.t = &t
+	return nil
+}
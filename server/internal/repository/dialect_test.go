@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+func TestRebindDollar(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "SELECT 1", want: "SELECT 1"},
+		{query: "SELECT * FROM diaries WHERE user_id = ?", want: "SELECT * FROM diaries WHERE user_id = $1"},
+		{query: "INSERT INTO t (a, b, c) VALUES (?, ?, ?)", want: "INSERT INTO t (a, b, c) VALUES ($1, $2, $3)"},
+		{query: "WHERE a IN (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", want: "WHERE a IN ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"},
+		{query: "WHERE name = ? -- 名前", want: "WHERE name = $1 -- 名前"},
+	}
+
+	for _, tt := range tests {
+		if got := rebindDollar(tt.query); got != tt.want {
+			t.Errorf("rebindDollar(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := "DELETE FROM schema_migrations WHERE version = ?"
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{driver: "postgres", want: "DELETE FROM schema_migrations WHERE version = $1"},
+		{driver: "mysql", want: query},
+		{driver: "sqlite", want: query},
+	}
+
+	for _, tt := range tests {
+		if got := Rebind(tt.driver, query); got != tt.want {
+			t.Errorf("Rebind(%s) = %q, want %q", tt.driver, got, tt.want)
+		}
+	}
+}
+
+func TestInsertIgnore(t *testing.T) {
+	query := "INSERT INTO diary_tags (diary_id, tag_id) SELECT diary_id, ? FROM diary_tags WHERE tag_id = ?"
+	tests := []struct {
+		d    dialect
+		want string
+	}{
+		{d: mysqlDialect, want: "INSERT IGNORE INTO diary_tags (diary_id, tag_id) SELECT diary_id, ? FROM diary_tags WHERE tag_id = ?"},
+		{d: postgresDialect, want: query + " ON CONFLICT DO NOTHING"},
+		{d: sqliteDialect, want: "INSERT OR IGNORE INTO diary_tags (diary_id, tag_id) SELECT diary_id, ? FROM diary_tags WHERE tag_id = ?"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.d.insertIgnore(query); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.d.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	// SQLite は実際のドライバーのエラーで確かめる
+	store := newTestStore(t)
+	now := time.Now().UTC()
+	_, duplicate := store.db.Exec("INSERT INTO streak_freezes (user_id, date, created_at) VALUES (?, ?, ?), (?, ?, ?)",
+		testUserID, "2026-01-01", now, testUserID, "2026-01-01", now)
+	_, foreignKey := store.db.Exec("INSERT INTO streak_freezes (user_id, date, created_at) VALUES (?, ?, ?)", "nobody", "2026-01-01", now)
+	if duplicate == nil || foreignKey == nil {
+		t.Fatalf("expected constraint errors: %v, %v", duplicate, foreignKey)
+	}
+
+	tests := []struct {
+		name string
+		d    dialect
+		err  error
+		want bool
+	}{
+		{name: "mysql duplicate entry", d: mysqlDialect, err: &mysql.MySQLError{Number: 1062}, want: true},
+		{name: "mysql wrapped", d: mysqlDialect, err: fmt.Errorf("insert: %w", &mysql.MySQLError{Number: 1062}), want: true},
+		{name: "mysql foreign key", d: mysqlDialect, err: &mysql.MySQLError{Number: 1452}, want: false},
+		{name: "postgres unique violation", d: postgresDialect, err: &pq.Error{Code: "23505"}, want: true},
+		{name: "postgres foreign key", d: postgresDialect, err: &pq.Error{Code: "23503"}, want: false},
+		{name: "postgres other driver", d: postgresDialect, err: &mysql.MySQLError{Number: 1062}, want: false},
+		{name: "sqlite primary key", d: sqliteDialect, err: duplicate, want: true},
+		{name: "sqlite foreign key", d: sqliteDialect, err: foreignKey, want: false},
+		{name: "plain error", d: sqliteDialect, err: errors.New("duplicate"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.isUniqueViolation(tt.err); got != tt.want {
+				t.Errorf("got %v, want %v (error: %v)", got, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestCreateDuplicateDiary(t *testing.T) {
+	store := newTestStore(t)
+	createDiary(t, store, "2026-01-01", 3, "A", "07:00", "23:00")
+
+	now := time.Now().UTC()
+	diary := &model.Diary{ID: "duplicate", UserID: testUserID, Date: "2026-01-01", Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", CreatedAt: now, UpdatedAt: now}
+	if err := store.Diaries.Create(diary); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("error = %v, want ErrDuplicate", err)
+	}
+}
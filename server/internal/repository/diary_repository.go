@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+const diaryColumns = `id, user_id, date, rating, progress, wake_up_time, sleep_time, COALESCE(memo, ''), created_at, updated_at`
+
+type sqlDiaryRepository struct {
+	db *sqlDB
+	d  dialect
+}
+
+func scanDiary(row interface{ Scan(...interface{}) error }, d *model.Diary) error {
+	return row.Scan(
+		&d.ID, &d.UserID, dateValue{&d.Date}, &d.Rating, &d.Progress,
+		clockValue{&d.WakeUpTime}, clockValue{&d.SleepTime}, &d.Memo, timeValue{&d.CreatedAt}, timeValue{&d.UpdatedAt},
+	)
+}
+
+// scanDiaries は rows の日記をすべて読み取る
+func scanDiaries(rows *sql.Rows) ([]model.Diary, error) {
+	var diaries []model.Diary
+	for rows.Next() {
+		var d model.Diary
+		if err := scanDiary(rows, &d); err != nil {
+			return nil, err
+		}
+		diaries = append(diaries, d)
+	}
+
+	return diaries, rows.Err()
+}
+
+func (r *sqlDiaryRepository) Create(diary *model.Diary) error {
+	query := `
+		INSERT INTO diaries (id, user_id, date, rating, progress, wake_up_time, sleep_time, memo, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(query, diary.ID, diary.UserID, diary.Date, diary.Rating, diary.Progress,
+		diary.WakeUpTime, diary.SleepTime, diary.Memo, diary.CreatedAt, diary.UpdatedAt)
+	if err != nil && r.d.isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *sqlDiaryRepository) GetByDate(userID, date string) (*model.Diary, error) {
+	query := `
+		SELECT ` + diaryColumns + `
+		FROM diaries
+		WHERE user_id = ? AND date = ?
+	`
+
+	diary := &model.Diary{}
+	err := scanDiary(r.db.QueryRow(query, userID, date), diary)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return diary, nil
+}
+
+// filterClause は filter を WHERE 句に追加する条件（先頭に AND が付く）とその引数に変換する
+func filterClause(userID string, filter DiaryFilter) (string, []interface{}, error) {
+	var clause string
+	var args []interface{}
+
+	if filter.StartDate != "" {
+		clause += " AND date >= ?"
+		args = append(args, filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		clause += " AND date <= ?"
+		args = append(args, filter.EndDate)
+	}
+	if filter.MinRating > 0 {
+		clause += " AND rating >= ?"
+		args = append(args, filter.MinRating)
+	}
+	if filter.MaxRating > 0 {
+		clause += " AND rating <= ?"
+		args = append(args, filter.MaxRating)
+	}
+	if len(filter.Tags) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(filter.Tags)), ", ")
+		sub := `
+			SELECT dt.diary_id
+			FROM diary_tags dt
+			JOIN tags t ON t.id = dt.tag_id
+			WHERE t.user_id = ? AND t.name IN (` + placeholders + `)
+		`
+		args = append(args, userID)
+		for _, name := range filter.Tags {
+			args = append(args, name)
+		}
+		if !filter.MatchAnyTag {
+			sub += " GROUP BY dt.diary_id HAVING COUNT(*) = ?"
+			args = append(args, len(filter.Tags))
+		}
+		clause += " AND id IN (" + sub + ")"
+	}
+	if filter.Expr != nil {
+		expr, exprArgs, err := exprClause(userID, *filter.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+		clause += " AND " + expr
+		args = append(args, exprArgs...)
+	}
+
+	return clause, args, nil
+}
+
+// filterColumns は絞り込み式の項目に対応する列（tag は別に扱う）
+var filterColumns = map[FilterField]string{
+	FilterDate:       "date",
+	FilterRating:     "rating",
+	FilterProgress:   "progress",
+	FilterWakeUpTime: "wake_up_time",
+	FilterSleepTime:  "sleep_time",
+}
+
+// exprClause は絞り込み式を条件に変換する。列名と演算子は決まったものだけを使い、値はすべて引数にする。
+func exprClause(userID string, e FilterExpr) (string, []interface{}, error) {
+	switch e.Op {
+	case FilterAnd, FilterOr:
+		parts := make([]string, len(e.Operands))
+		var args []interface{}
+		for i, operand := range e.Operands {
+			part, partArgs, err := exprClause(userID, operand)
+			if err != nil {
+				return "", nil, err
+			}
+			parts[i] = part
+			args = append(args, partArgs...)
+		}
+		return "(" + strings.Join(parts, " "+string(e.Op)+" ") + ")", args, nil
+	case FilterNot:
+		if len(e.Operands) != 1 {
+			return "", nil, fmt.Errorf("NOT requires exactly one operand, got %d", len(e.Operands))
+		}
+		part, args, err := exprClause(userID, e.Operands[0])
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT " + part, args, nil
+	case FilterEq, FilterNe, FilterLt, FilterLe, FilterGt, FilterGe:
+	default:
+		return "", nil, fmt.Errorf("unsupported filter operator %q", e.Op)
+	}
+
+	if e.Field == FilterTag {
+		if e.Op != FilterEq && e.Op != FilterNe {
+			return "", nil, fmt.Errorf("unsupported operator %q for tag", e.Op)
+		}
+		in := "id IN"
+		if e.Op == FilterNe {
+			in = "id NOT IN"
+		}
+		return in + ` (
+			SELECT dt.diary_id
+			FROM diary_tags dt
+			JOIN tags t ON t.id = dt.tag_id
+			WHERE t.user_id = ? AND t.name = ?
+		)`, []interface{}{userID, e.Value}, nil
+	}
+
+	column, ok := filterColumns[e.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported filter field %q", e.Field)
+	}
+	return "(" + column + " " + string(e.Op) + " ?)", []interface{}{e.Value}, nil
+}
+
+// orderColumns は日記一覧の並び順に使える列
+var orderColumns = map[string]string{
+	"date":       "date",
+	"rating":     "rating",
+	"updated_at": "updated_at",
+}
+
+// orderClause は order を ORDER BY 句に変換する（既定は日付の降順）
+func orderClause(order DiaryOrder) (string, error) {
+	field := order.Field
+	if field == "" {
+		field = "date"
+	}
+	column, ok := orderColumns[field]
+	if !ok {
+		return "", fmt.Errorf("unsupported sort field %q", order.Field)
+	}
+	direction := "DESC"
+	if order.Ascending {
+		direction = "ASC"
+	}
+
+	clause := " ORDER BY " + column + " " + direction
+	if column != "date" {
+		clause += ", date " + direction
+	}
+	return clause, nil
+}
+
+func (r *sqlDiaryRepository) GetAll(userID string, filter DiaryFilter, order DiaryOrder, limit, offset int) ([]model.Diary, error) {
+	clause, filterArgs, err := filterClause(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err := orderClause(order)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		SELECT ` + diaryColumns + `
+		FROM diaries
+		WHERE user_id = ?` + clause + orderBy + `
+		LIMIT ? OFFSET ?
+	`
+	args := append([]interface{}{userID}, filterArgs...)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDiaries(rows)
+}
+
+func (r *sqlDiaryRepository) Count(userID string, filter DiaryFilter) (int, error) {
+	clause, filterArgs, err := filterClause(userID, filter)
+	if err != nil {
+		return 0, err
+	}
+	query := `SELECT COUNT(*) FROM diaries WHERE user_id = ?` + clause
+
+	var count int
+	if err := r.db.QueryRow(query, append([]interface{}{userID}, filterArgs...)...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *sqlDiaryRepository) GetRange(userID, startDate, endDate string) ([]model.Diary, error) {
+	query := `
+		SELECT ` + diaryColumns + `
+		FROM diaries
+		WHERE user_id = ? AND date >= ? AND date <= ?
+		ORDER BY date
+	`
+
+	rows, err := r.db.Query(query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDiaries(rows)
+}
+
+func (r *sqlDiaryRepository) Update(diary *model.Diary) error {
+	query := `
+		UPDATE diaries
+		SET rating = ?, progress = ?, wake_up_time = ?, sleep_time = ?, memo = ?, updated_at = ?
+		WHERE user_id = ? AND date = ?
+	`
+	_, err := r.db.Exec(query, diary.Rating, diary.Progress, diary.WakeUpTime, diary.SleepTime,
+		diary.Memo, diary.UpdatedAt, diary.UserID, diary.Date)
+	return err
+}
+
+func (r *sqlDiaryRepository) Delete(userID, date string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM diaries WHERE user_id = ? AND date = ?", userID, date)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *sqlDiaryRepository) GetCalendarEntries(userID string, filter DiaryFilter) ([]model.CalendarEntry, error) {
+	clause, filterArgs, err := filterClause(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		SELECT date, rating, progress, CASE WHEN TRIM(COALESCE(memo, '')) = '' THEN 0 ELSE 1 END
+		FROM diaries
+		WHERE user_id = ?` + clause + `
+		ORDER BY date
+	`
+
+	rows, err := r.db.Query(query, append([]interface{}{userID}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.CalendarEntry
+	for rows.Next() {
+		var e model.CalendarEntry
+		if err := rows.Scan(dateValue{&e.Date}, &e.Rating, &e.Progress, &e.HasMemo); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *sqlDiaryRepository) GetStatistics(userID, startDate, endDate string) (*model.Statistics, error) {
+	query := `
+		SELECT
+			COUNT(*) as total,
+			AVG(rating) as avg_rating,
+			COALESCE(SUM(CASE WHEN rating = 1 THEN 1 ELSE 0 END), 0) as r1,
+			COALESCE(SUM(CASE WHEN rating = 2 THEN 1 ELSE 0 END), 0) as r2,
+			COALESCE(SUM(CASE WHEN rating = 3 THEN 1 ELSE 0 END), 0) as r3,
+			COALESCE(SUM(CASE WHEN rating = 4 THEN 1 ELSE 0 END), 0) as r4,
+			COALESCE(SUM(CASE WHEN rating = 5 THEN 1 ELSE 0 END), 0) as r5,
+			COALESCE(SUM(CASE WHEN progress = 'A' THEN 1 ELSE 0 END), 0) as pa,
+			COALESCE(SUM(CASE WHEN progress = 'B' THEN 1 ELSE 0 END), 0) as pb,
+			COALESCE(SUM(CASE WHEN progress = 'C' THEN 1 ELSE 0 END), 0) as pc
+		FROM diaries
+		WHERE user_id = ? AND date >= ? AND date <= ?
+	`
+
+	stats := &model.Statistics{}
+	var avgRating sql.NullFloat64
+	var r1, r2, r3, r4, r5, pa, pb, pc int
+
+	err := r.db.QueryRow(query, userID, startDate, endDate).Scan(
+		&stats.TotalEntries, &avgRating,
+		&r1, &r2, &r3, &r4, &r5,
+		&pa, &pb, &pc,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if avgRating.Valid {
+		stats.AverageRating = avgRating.Float64
+	}
+
+	stats.RatingDistribution = map[string]int{"1": r1, "2": r2, "3": r3, "4": r4, "5": r5}
+	stats.ProgressDistribution = map[string]int{"A": pa, "B": pb, "C": pc}
+
+	return stats, nil
+}
+
+func (r *sqlDiaryRepository) GetDates(userID string) ([]string, error) {
+	query := `
+		SELECT date FROM diaries
+		WHERE user_id = ?
+		ORDER BY date
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []string
+	for rows.Next() {
+		var d string
+		if err := rows.Scan(dateValue{&d}); err != nil {
+			return nil, err
+		}
+		dates = append(dates, d)
+	}
+
+	return dates, rows.Err()
+}
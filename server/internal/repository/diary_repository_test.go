@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// compact は SQL の連続する空白を1つにする
+func compact(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func TestFilterClause(t *testing.T) {
+	rating := FilterExpr{Op: FilterGe, Field: FilterRating, Value: 4}
+	tests := []struct {
+		name     string
+		filter   DiaryFilter
+		want     string
+		wantArgs []interface{}
+	}{
+		{name: "empty", filter: DiaryFilter{}, want: "", wantArgs: nil},
+		{
+			name:     "dates and ratings",
+			filter:   DiaryFilter{StartDate: "2026-01-01", EndDate: "2026-01-31", MinRating: 2, MaxRating: 4},
+			want:     "AND date >= ? AND date <= ? AND rating >= ? AND rating <= ?",
+			wantArgs: []interface{}{"2026-01-01", "2026-01-31", 2, 4},
+		},
+		{
+			name:     "all tags",
+			filter:   DiaryFilter{Tags: []string{"work", "gym"}},
+			want:     "AND id IN ( SELECT dt.diary_id FROM diary_tags dt JOIN tags t ON t.id = dt.tag_id WHERE t.user_id = ? AND t.name IN (?, ?) GROUP BY dt.diary_id HAVING COUNT(*) = ?)",
+			wantArgs: []interface{}{testUserID, "work", "gym", 2},
+		},
+		{
+			name:     "any tag",
+			filter:   DiaryFilter{Tags: []string{"work", "gym"}, MatchAnyTag: true},
+			want:     "AND id IN ( SELECT dt.diary_id FROM diary_tags dt JOIN tags t ON t.id = dt.tag_id WHERE t.user_id = ? AND t.name IN (?, ?) )",
+			wantArgs: []interface{}{testUserID, "work", "gym"},
+		},
+		{
+			name:     "expression after dates",
+			filter:   DiaryFilter{StartDate: "2026-01-01", Expr: &rating},
+			want:     "AND date >= ? AND (rating >= ?)",
+			wantArgs: []interface{}{"2026-01-01", 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := filterClause(testUserID, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if compact(got) != tt.want {
+				t.Errorf("clause = %q, want %q", compact(got), tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestExprClause(t *testing.T) {
+	cmp := func(field FilterField, op FilterOp, value interface{}) FilterExpr {
+		return FilterExpr{Op: op, Field: field, Value: value}
+	}
+	tests := []struct {
+		name     string
+		expr     FilterExpr
+		want     string
+		wantArgs []interface{}
+	}{
+		{name: "comparison", expr: cmp(FilterSleepTime, FilterLt, "23:00"), want: "(sleep_time < ?)", wantArgs: []interface{}{"23:00"}},
+		{name: "not equal", expr: cmp(FilterProgress, FilterNe, "C"), want: "(progress != ?)", wantArgs: []interface{}{"C"}},
+		{
+			name:     "and",
+			expr:     FilterExpr{Op: FilterAnd, Operands: []FilterExpr{cmp(FilterRating, FilterGe, 4), cmp(FilterDate, FilterLe, "2026-01-31")}},
+			want:     "((rating >= ?) AND (date <= ?))",
+			wantArgs: []interface{}{4, "2026-01-31"},
+		},
+		{
+			name: "nested or and not",
+			expr: FilterExpr{Op: FilterOr, Operands: []FilterExpr{
+				cmp(FilterWakeUpTime, FilterGt, "08:00"),
+				{Op: FilterNot, Operands: []FilterExpr{cmp(FilterProgress, FilterEq, "A")}},
+			}},
+			want:     "((wake_up_time > ?) OR NOT (progress = ?))",
+			wantArgs: []interface{}{"08:00", "A"},
+		},
+		{
+			name:     "has tag",
+			expr:     cmp(FilterTag, FilterEq, "work"),
+			want:     "id IN ( SELECT dt.diary_id FROM diary_tags dt JOIN tags t ON t.id = dt.tag_id WHERE t.user_id = ? AND t.name = ? )",
+			wantArgs: []interface{}{testUserID, "work"},
+		},
+		{
+			name:     "does not have tag",
+			expr:     cmp(FilterTag, FilterNe, "work"),
+			want:     "id NOT IN ( SELECT dt.diary_id FROM diary_tags dt JOIN tags t ON t.id = dt.tag_id WHERE t.user_id = ? AND t.name = ? )",
+			wantArgs: []interface{}{testUserID, "work"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := exprClause(testUserID, tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if compact(got) != tt.want {
+				t.Errorf("clause = %q, want %q", compact(got), tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestExprClauseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr FilterExpr
+	}{
+		{name: "unknown operator", expr: FilterExpr{Op: "LIKE", Field: FilterProgress, Value: "A"}},
+		{name: "unknown field", expr: FilterExpr{Op: FilterEq, Field: "memo", Value: "x"}},
+		{name: "ordering a tag", expr: FilterExpr{Op: FilterLt, Field: FilterTag, Value: "work"}},
+		{name: "not without operand", expr: FilterExpr{Op: FilterNot}},
+		{name: "invalid operand", expr: FilterExpr{Op: FilterAnd, Operands: []FilterExpr{{Op: FilterEq, Field: "user_id", Value: "x"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := exprClause(testUserID, tt.expr); err == nil {
+				t.Error("expected an error")
+			}
+			if _, _, err := filterClause(testUserID, DiaryFilter{Expr: &tt.expr}); err == nil {
+				t.Error("filterClause: expected an error")
+			}
+		})
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	tests := []struct {
+		order   DiaryOrder
+		want    string
+		wantErr bool
+	}{
+		{order: DiaryOrder{}, want: " ORDER BY date DESC"},
+		{order: DiaryOrder{Field: "date", Ascending: true}, want: " ORDER BY date ASC"},
+		{order: DiaryOrder{Field: "rating"}, want: " ORDER BY rating DESC, date DESC"},
+		{order: DiaryOrder{Field: "updated_at", Ascending: true}, want: " ORDER BY updated_at ASC, date ASC"},
+		{order: DiaryOrder{Field: "memo"}, wantErr: true},
+		{order: DiaryOrder{Field: "rating; DROP TABLE diaries"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := orderClause(tt.order)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("orderClause(%+v): error = %v, want error %v", tt.order, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("orderClause(%+v) = %q, want %q", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestGetAllFilter(t *testing.T) {
+	store := newTestStore(t)
+	ids := map[string]string{
+		"2026-01-01": createDiary(t, store, "2026-01-01", 5, "A", "06:30", "22:30"),
+		"2026-01-02": createDiary(t, store, "2026-01-02", 4, "B", "07:30", "23:30"),
+		"2026-01-03": createDiary(t, store, "2026-01-03", 2, "C", "09:00", "01:30"),
+		"2026-01-04": createDiary(t, store, "2026-01-04", 4, "A", "08:00", "00:30"),
+	}
+	for date, names := range map[string][]string{"2026-01-01": {"work"}, "2026-01-02": {"work", "gym"}, "2026-01-04": {"gym"}} {
+		if err := store.Tags.SetDiaryTags(testUserID, ids[date], names); err != nil {
+			t.Fatalf("set tags: %v", err)
+		}
+	}
+
+	notTag := FilterExpr{Op: FilterNe, Field: FilterTag, Value: "work"}
+	goodOrEarly := FilterExpr{Op: FilterOr, Operands: []FilterExpr{
+		{Op: FilterEq, Field: FilterRating, Value: 5},
+		{Op: FilterLt, Field: FilterWakeUpTime, Value: "08:00"},
+	}}
+	tests := []struct {
+		name   string
+		filter DiaryFilter
+		order  DiaryOrder
+		want   []string
+	}{
+		{name: "all", want: []string{"2026-01-04", "2026-01-03", "2026-01-02", "2026-01-01"}},
+		{name: "date range", filter: DiaryFilter{StartDate: "2026-01-02", EndDate: "2026-01-03"}, want: []string{"2026-01-03", "2026-01-02"}},
+		{name: "all tags", filter: DiaryFilter{Tags: []string{"work", "gym"}}, want: []string{"2026-01-02"}},
+		{name: "any tag", filter: DiaryFilter{Tags: []string{"work", "gym"}, MatchAnyTag: true}, want: []string{"2026-01-04", "2026-01-02", "2026-01-01"}},
+		{name: "without tag", filter: DiaryFilter{Expr: &notTag}, want: []string{"2026-01-04", "2026-01-03"}},
+		{name: "or", filter: DiaryFilter{Expr: &goodOrEarly}, order: DiaryOrder{Ascending: true}, want: []string{"2026-01-01", "2026-01-02"}},
+		{name: "by rating", order: DiaryOrder{Field: "rating"}, want: []string{"2026-01-01", "2026-01-04", "2026-01-02", "2026-01-03"}},
+		{name: "by rating ascending", order: DiaryOrder{Field: "rating", Ascending: true}, want: []string{"2026-01-03", "2026-01-02", "2026-01-04", "2026-01-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diaries, err := store.Diaries.GetAll(testUserID, tt.filter, tt.order, 10, 0)
+			if err != nil {
+				t.Fatalf("get all: %v", err)
+			}
+			if got := diaryDates(diaries); !slices.Equal(got, tt.want) {
+				t.Errorf("dates = %v, want %v", got, tt.want)
+			}
+			count, err := store.Diaries.Count(testUserID, tt.filter)
+			if err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("count = %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,1080 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+// NewMemoryStore はプロセス内メモリにデータを保持するバックエンドを返す。
+// 再起動でデータは消えるため、開発・テスト用。
+func NewMemoryStore() *Store {
+	diaries := &memoryDiaryRepository{diaries: map[string]map[string]model.Diary{}}
+	tags := &memoryTagRepository{tags: map[string]map[string]model.Tag{}, diaryTags: map[string]map[string]bool{}, diaries: diaries}
+	metrics := &memoryMetricRepository{metrics: map[string]map[string]model.Metric{}, values: map[string]map[string]float64{}, diaries: diaries}
+	search := &memorySearchIndexRepository{docs: map[string]memorySearchDoc{}, diaries: diaries}
+	diaries.tags = tags
+	diaries.metrics = metrics
+	diaries.search = search
+	repos := Repos{Diaries: diaries, Tags: tags, Metrics: metrics, SearchIndex: search}
+	var txMu sync.Mutex
+
+	return &Store{
+		Diaries:       diaries,
+		Users:         &memoryUserRepository{users: map[string]model.User{}, settings: map[string]model.UserSettings{}},
+		RefreshTokens: &memoryRefreshTokenRepository{tokens: map[string]model.RefreshToken{}},
+		StreakFreezes: &memoryStreakFreezeRepository{freezes: map[string]map[string]time.Time{}},
+		Tags:          tags,
+		Metrics:       metrics,
+		SearchIndex:   search,
+		driver:        "memory",
+		// トランザクション同士は順に実行するが、途中で失敗しても書き込みは取り消さない
+		withTx: func(fn func(Repos) error) error {
+			txMu.Lock()
+			defer txMu.Unlock()
+			return fn(repos)
+		},
+	}
+}
+
+type memoryDiaryRepository struct {
+	mu sync.RWMutex
+	// user_id -> date -> diary
+	diaries map[string]map[string]model.Diary
+	tags    *memoryTagRepository
+	metrics *memoryMetricRepository
+	search  *memorySearchIndexRepository
+}
+
+// filtered は filter に一致する日記を日付の昇順で返す。
+// タグの条件は日記のロックを取る前に解決し、2つのロックを同時に持たないようにする。
+func (r *memoryDiaryRepository) filtered(userID string, filter DiaryFilter) []model.Diary {
+	var ids map[string]bool
+	if len(filter.Tags) > 0 {
+		ids = r.tags.diaryIDsWith(userID, filter.Tags, filter.MatchAnyTag)
+	}
+	var tagNames map[string]map[string]bool
+	if filter.Expr != nil {
+		tagNames = r.tags.namesByDiary(userID)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []model.Diary
+	for _, d := range r.sorted(userID, filter.StartDate, filter.EndDate) {
+		if ids != nil && !ids[d.ID] {
+			continue
+		}
+		if (filter.MinRating > 0 && d.Rating < filter.MinRating) || (filter.MaxRating > 0 && d.Rating > filter.MaxRating) {
+			continue
+		}
+		if filter.Expr != nil && !matchExpr(d, *filter.Expr, tagNames[d.ID]) {
+			continue
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// matchExpr は日記が絞り込み式に一致するかどうかを返す。tags は日記に付いたタグ名。
+// 日付・時刻は固定長の文字列のため、文字列の大小で比べる。
+func matchExpr(d model.Diary, e FilterExpr, tags map[string]bool) bool {
+	switch e.Op {
+	case FilterAnd:
+		for _, operand := range e.Operands {
+			if !matchExpr(d, operand, tags) {
+				return false
+			}
+		}
+		return true
+	case FilterOr:
+		for _, operand := range e.Operands {
+			if matchExpr(d, operand, tags) {
+				return true
+			}
+		}
+		return false
+	case FilterNot:
+		return len(e.Operands) == 1 && !matchExpr(d, e.Operands[0], tags)
+	}
+
+	var cmp int
+	switch e.Field {
+	case FilterTag:
+		name, _ := e.Value.(string)
+		return tags[name] == (e.Op == FilterEq)
+	case FilterRating:
+		v, _ := e.Value.(int)
+		cmp = d.Rating - v
+	case FilterDate:
+		cmp = strings.Compare(d.Date, fmt.Sprint(e.Value))
+	case FilterProgress:
+		cmp = strings.Compare(d.Progress, fmt.Sprint(e.Value))
+	case FilterWakeUpTime:
+		cmp = strings.Compare(d.WakeUpTime, fmt.Sprint(e.Value))
+	case FilterSleepTime:
+		cmp = strings.Compare(d.SleepTime, fmt.Sprint(e.Value))
+	default:
+		return false
+	}
+
+	switch e.Op {
+	case FilterEq:
+		return cmp == 0
+	case FilterNe:
+		return cmp != 0
+	case FilterLt:
+		return cmp < 0
+	case FilterLe:
+		return cmp <= 0
+	case FilterGt:
+		return cmp > 0
+	case FilterGe:
+		return cmp >= 0
+	}
+	return false
+}
+
+// sorted は期間内の日記を日付の昇順で返す（空文字は無制限）
+func (r *memoryDiaryRepository) sorted(userID, startDate, endDate string) []model.Diary {
+	var result []model.Diary
+	for date, d := range r.diaries[userID] {
+		if startDate != "" && date < startDate {
+			continue
+		}
+		if endDate != "" && date > endDate {
+			continue
+		}
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+func (r *memoryDiaryRepository) Create(diary *model.Diary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byDate, ok := r.diaries[diary.UserID]
+	if !ok {
+		byDate = map[string]model.Diary{}
+		r.diaries[diary.UserID] = byDate
+	}
+	if _, exists := byDate[diary.Date]; exists {
+		return ErrDuplicate
+	}
+	byDate[diary.Date] = *diary
+	return nil
+}
+
+func (r *memoryDiaryRepository) GetByDate(userID, date string) (*model.Diary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.diaries[userID][date]
+	if !ok {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+func (r *memoryDiaryRepository) GetAll(userID string, filter DiaryFilter, order DiaryOrder, limit, offset int) ([]model.Diary, error) {
+	all := r.filtered(userID, filter)
+
+	// all は日付の昇順のため、安定ソートで同じ値の日記は日付順のまま残る
+	var less func(a, b model.Diary) bool
+	switch order.Field {
+	case "", "date":
+	case "rating":
+		less = func(a, b model.Diary) bool { return a.Rating < b.Rating }
+	case "updated_at":
+		less = func(a, b model.Diary) bool { return a.UpdatedAt.Before(b.UpdatedAt) }
+	default:
+		return nil, fmt.Errorf("unsupported sort field %q", order.Field)
+	}
+	if less != nil {
+		sort.SliceStable(all, func(i, j int) bool { return less(all[i], all[j]) })
+	}
+	if !order.Ascending {
+		slices.Reverse(all)
+	}
+
+	if offset >= len(all) {
+		return nil, nil
+	}
+	all = all[offset:]
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+func (r *memoryDiaryRepository) Count(userID string, filter DiaryFilter) (int, error) {
+	return len(r.filtered(userID, filter)), nil
+}
+
+func (r *memoryDiaryRepository) GetRange(userID, startDate, endDate string) ([]model.Diary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sorted(userID, startDate, endDate), nil
+}
+
+func (r *memoryDiaryRepository) Update(diary *model.Diary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.diaries[diary.UserID][diary.Date]; ok {
+		r.diaries[diary.UserID][diary.Date] = *diary
+	}
+	return nil
+}
+
+func (r *memoryDiaryRepository) Delete(userID, date string) (bool, error) {
+	r.mu.Lock()
+	d, ok := r.diaries[userID][date]
+	if ok {
+		delete(r.diaries[userID], date)
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	r.tags.removeDiary(d.ID)
+	r.metrics.removeDiary(d.ID)
+	r.search.removeDiary(d.ID)
+	return true, nil
+}
+
+func (r *memoryDiaryRepository) GetCalendarEntries(userID string, filter DiaryFilter) ([]model.CalendarEntry, error) {
+	var entries []model.CalendarEntry
+	for _, d := range r.filtered(userID, filter) {
+		entries = append(entries, model.CalendarEntry{
+			Date:     d.Date,
+			Rating:   d.Rating,
+			Progress: d.Progress,
+			HasMemo:  strings.TrimSpace(d.Memo) != "",
+		})
+	}
+	return entries, nil
+}
+
+func (r *memoryDiaryRepository) GetStatistics(userID, startDate, endDate string) (*model.Statistics, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &model.Statistics{
+		RatingDistribution:   map[string]int{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0},
+		ProgressDistribution: map[string]int{"A": 0, "B": 0, "C": 0},
+	}
+
+	total := 0
+	for _, d := range r.sorted(userID, startDate, endDate) {
+		stats.TotalEntries++
+		total += d.Rating
+		stats.RatingDistribution[strconv.Itoa(d.Rating)]++
+		stats.ProgressDistribution[d.Progress]++
+	}
+	if stats.TotalEntries > 0 {
+		stats.AverageRating = float64(total) / float64(stats.TotalEntries)
+	}
+
+	return stats, nil
+}
+
+func (r *memoryDiaryRepository) GetDates(userID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var dates []string
+	for _, d := range r.sorted(userID, "", "") {
+		dates = append(dates, d.Date)
+	}
+	return dates, nil
+}
+
+type memoryUserRepository struct {
+	mu sync.RWMutex
+	// id -> user
+	users map[string]model.User
+	// id -> settings（未設定の場合は初期値）
+	settings map[string]model.UserSettings
+}
+
+func (r *memoryUserRepository) conflicts(user *model.User) bool {
+	for _, u := range r.users {
+		if u.ID == user.ID || u.Email == user.Email || u.Username == user.Username {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryUserRepository) Create(user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conflicts(user) {
+		return ErrDuplicate
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) EnsureExists(user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.conflicts(user) {
+		r.users[user.ID] = *user
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) GetByEmail(email string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryUserRepository) ExistsByEmail(email string) (bool, error) {
+	u, err := r.GetByEmail(email)
+	return u != nil, err
+}
+
+func (r *memoryUserRepository) ExistsByUsername(username string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryUserRepository) GetSettings(userID string) (*model.UserSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.users[userID]; !ok {
+		return nil, nil
+	}
+	settings, ok := r.settings[userID]
+	if !ok {
+		settings = model.UserSettings{SleepTargetMinutes: model.DefaultSleepTargetMinutes}
+	}
+	return &settings, nil
+}
+
+func (r *memoryUserRepository) UpdateSettings(userID string, settings *model.UserSettings) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[userID]; !ok {
+		return false, nil
+	}
+	r.settings[userID] = *settings
+	return true, nil
+}
+
+type memoryRefreshTokenRepository struct {
+	mu sync.Mutex
+	// id -> token
+	tokens map[string]model.RefreshToken
+}
+
+func (r *memoryRefreshTokenRepository) Create(token *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.ID]; exists {
+		return ErrDuplicate
+	}
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) GetByID(userID, id string) (*model.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok || t.UserID != userID {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (r *memoryRefreshTokenRepository) MarkUsed(userID, id string, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok || t.UserID != userID || t.UsedAt != nil || t.RevokedAt != nil || !t.ExpiresAt.After(now) {
+		return false, nil
+	}
+	t.UsedAt = &now
+	r.tokens[id] = t
+	return true, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(userID, familyID string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, t := range r.tokens {
+		if t.UserID == userID && t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+			r.tokens[id] = t
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) FamilyActive(userID, familyID string, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.FamilyID == familyID && t.RevokedAt == nil && t.ExpiresAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryRefreshTokenRepository) ListSessions(userID string, now time.Time) ([]model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	started := map[string]time.Time{}
+	for _, t := range r.tokens {
+		if first, ok := started[t.FamilyID]; !ok || t.CreatedAt.Before(first) {
+			started[t.FamilyID] = t.CreatedAt
+		}
+	}
+
+	sessions := []model.Session{}
+	for _, t := range r.tokens {
+		if t.UserID != userID || t.UsedAt != nil || t.RevokedAt != nil || !t.ExpiresAt.After(now) {
+			continue
+		}
+		sessions = append(sessions, model.Session{
+			ID:         t.FamilyID,
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			CreatedAt:  started[t.FamilyID],
+			LastUsedAt: t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+
+	return sessions, nil
+}
+
+type memoryStreakFreezeRepository struct {
+	mu sync.RWMutex
+	// user_id -> date -> created_at
+	freezes map[string]map[string]time.Time
+}
+
+func (r *memoryStreakFreezeRepository) Create(userID, date string, createdAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byDate, ok := r.freezes[userID]
+	if !ok {
+		byDate = map[string]time.Time{}
+		r.freezes[userID] = byDate
+	}
+	if _, exists := byDate[date]; exists {
+		return ErrDuplicate
+	}
+	byDate[date] = createdAt
+	return nil
+}
+
+func (r *memoryStreakFreezeRepository) Delete(userID, date string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.freezes[userID][date]; !ok {
+		return false, nil
+	}
+	delete(r.freezes[userID], date)
+	return true, nil
+}
+
+func (r *memoryStreakFreezeRepository) List(userID, startDate, endDate string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var dates []string
+	for date := range r.freezes[userID] {
+		if startDate != "" && date < startDate {
+			continue
+		}
+		if endDate != "" && date > endDate {
+			continue
+		}
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates, nil
+}
+
+type memoryTagRepository struct {
+	mu sync.RWMutex
+	// user_id -> tag_id -> tag（DiaryCount は使わない）
+	tags map[string]map[string]model.Tag
+	// diary_id -> tag_id
+	diaryTags map[string]map[string]bool
+	// diaries は Stats で評価を参照する（タグのロックを持ったまま日記のロックは取らない）
+	diaries *memoryDiaryRepository
+}
+
+// countDiaries は tagID が付いた日記の数を返す（呼び出し側でロックを持つ）
+func (r *memoryTagRepository) countDiaries(tagID string) int {
+	n := 0
+	for _, ids := range r.diaryTags {
+		if ids[tagID] {
+			n++
+		}
+	}
+	return n
+}
+
+// diaryIDsWith は names のすべて（matchAny の場合はいずれか）が付いた日記IDを返す
+func (r *memoryTagRepository) diaryIDsWith(userID string, names []string, matchAny bool) map[string]bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := map[string]bool{}
+	for id, t := range r.tags[userID] {
+		for _, name := range names {
+			if t.Name == name {
+				wanted[id] = true
+			}
+		}
+	}
+
+	result := map[string]bool{}
+	for diaryID, ids := range r.diaryTags {
+		matched := 0
+		for id := range ids {
+			if wanted[id] {
+				matched++
+			}
+		}
+		if (matchAny && matched > 0) || (!matchAny && matched == len(names)) {
+			result[diaryID] = true
+		}
+	}
+	return result
+}
+
+// namesByDiary は userID の日記ごとに付いているタグ名を返す
+func (r *memoryTagRepository) namesByDiary(userID string) map[string]map[string]bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := map[string]map[string]bool{}
+	for diaryID, ids := range r.diaryTags {
+		for id := range ids {
+			t, ok := r.tags[userID][id]
+			if !ok {
+				continue
+			}
+			if result[diaryID] == nil {
+				result[diaryID] = map[string]bool{}
+			}
+			result[diaryID][t.Name] = true
+		}
+	}
+	return result
+}
+
+func (r *memoryTagRepository) removeDiary(diaryID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.diaryTags, diaryID)
+}
+
+func (r *memoryTagRepository) List(userID string) ([]model.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tags []model.Tag
+	for _, t := range r.tags[userID] {
+		t.DiaryCount = r.countDiaries(t.ID)
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *memoryTagRepository) GetByID(userID, id string) (*model.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tags[userID][id]
+	if !ok {
+		return nil, nil
+	}
+	t.DiaryCount = r.countDiaries(id)
+	return &t, nil
+}
+
+func (r *memoryTagRepository) SetDiaryTags(userID, diaryID string, names []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byID, ok := r.tags[userID]
+	if !ok {
+		byID = map[string]model.Tag{}
+		r.tags[userID] = byID
+	}
+
+	ids := map[string]bool{}
+	for _, name := range names {
+		var tagID string
+		for id, t := range byID {
+			if t.Name == name {
+				tagID = id
+			}
+		}
+		if tagID == "" {
+			tagID = uuid.New().String()
+			byID[tagID] = model.Tag{ID: tagID, Name: name, CreatedAt: time.Now().UTC()}
+		}
+		ids[tagID] = true
+	}
+	r.diaryTags[diaryID] = ids
+	return nil
+}
+
+func (r *memoryTagRepository) ListForDiaries(diaryIDs []string) (map[string][]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// タグIDから名前を引けるようにする
+	names := map[string]string{}
+	for _, byID := range r.tags {
+		for id, t := range byID {
+			names[id] = t.Name
+		}
+	}
+
+	result := make(map[string][]string, len(diaryIDs))
+	for _, diaryID := range diaryIDs {
+		for id := range r.diaryTags[diaryID] {
+			result[diaryID] = append(result[diaryID], names[id])
+		}
+		sort.Strings(result[diaryID])
+	}
+	return result, nil
+}
+
+func (r *memoryTagRepository) Rename(userID, id, name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tags[userID][id]
+	if !ok {
+		return false, nil
+	}
+	for otherID, other := range r.tags[userID] {
+		if otherID != id && other.Name == name {
+			return false, ErrDuplicate
+		}
+	}
+	t.Name = name
+	r.tags[userID][id] = t
+	return true, nil
+}
+
+func (r *memoryTagRepository) Merge(userID, sourceID, targetID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tags[userID][sourceID]; !ok {
+		return nil
+	}
+	for _, ids := range r.diaryTags {
+		if ids[sourceID] {
+			delete(ids, sourceID)
+			ids[targetID] = true
+		}
+	}
+	delete(r.tags[userID], sourceID)
+	return nil
+}
+
+func (r *memoryTagRepository) Delete(userID, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tags[userID][id]; !ok {
+		return false, nil
+	}
+	for _, ids := range r.diaryTags {
+		delete(ids, id)
+	}
+	delete(r.tags[userID], id)
+	return true, nil
+}
+
+func (r *memoryTagRepository) Stats(userID, startDate, endDate string) ([]model.TagStat, error) {
+	// 日記ごとのタグ名を控えてからロックを外し、日記を参照する
+	r.mu.RLock()
+	tagNames := map[string][]string{}
+	for diaryID, ids := range r.diaryTags {
+		for id := range ids {
+			if t, ok := r.tags[userID][id]; ok {
+				tagNames[diaryID] = append(tagNames[diaryID], t.Name)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	diaries, err := r.diaries.GetRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]*model.TagStat{}
+	ratingSums := map[string]int{}
+	for _, d := range diaries {
+		for _, name := range tagNames[d.ID] {
+			stat, ok := byName[name]
+			if !ok {
+				stat = &model.TagStat{Name: name}
+				byName[name] = stat
+			}
+			stat.TotalEntries++
+			ratingSums[name] += d.Rating
+		}
+	}
+
+	var stats []model.TagStat
+	for name, stat := range byName {
+		stat.AverageRating = float64(ratingSums[name]) / float64(stat.TotalEntries)
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalEntries != stats[j].TotalEntries {
+			return stats[i].TotalEntries > stats[j].TotalEntries
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats, nil
+}
+
+type memoryMetricRepository struct {
+	mu sync.RWMutex
+	// user_id -> metric_id -> metric
+	metrics map[string]map[string]model.Metric
+	// diary_id -> metric_id -> value
+	values map[string]map[string]float64
+	// diaries は ListValues で日付を参照する（記録項目のロックを持ったまま日記のロックは取らない）
+	diaries *memoryDiaryRepository
+}
+
+func (r *memoryMetricRepository) removeDiary(diaryID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.values, diaryID)
+}
+
+// nameTaken は同じ名前の別の記録項目があるかを返す（呼び出し側でロックを持つ）
+func (r *memoryMetricRepository) nameTaken(metric *model.Metric) bool {
+	for id, m := range r.metrics[metric.UserID] {
+		if id != metric.ID && m.Name == metric.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryMetricRepository) List(userID string) ([]model.Metric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var metrics []model.Metric
+	for _, m := range r.metrics[userID] {
+		metrics = append(metrics, m)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if !metrics[i].CreatedAt.Equal(metrics[j].CreatedAt) {
+			return metrics[i].CreatedAt.Before(metrics[j].CreatedAt)
+		}
+		return metrics[i].Name < metrics[j].Name
+	})
+	return metrics, nil
+}
+
+func (r *memoryMetricRepository) GetByID(userID, id string) (*model.Metric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.metrics[userID][id]
+	if !ok {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+func (r *memoryMetricRepository) Create(metric *model.Metric) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(metric) {
+		return ErrDuplicate
+	}
+	byID, ok := r.metrics[metric.UserID]
+	if !ok {
+		byID = map[string]model.Metric{}
+		r.metrics[metric.UserID] = byID
+	}
+	byID[metric.ID] = *metric
+	return nil
+}
+
+func (r *memoryMetricRepository) Update(metric *model.Metric) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.metrics[metric.UserID][metric.ID]
+	if !ok {
+		return false, nil
+	}
+	if r.nameTaken(metric) {
+		return false, ErrDuplicate
+	}
+	m.Name, m.Unit, m.Min, m.Max, m.UpdatedAt = metric.Name, metric.Unit, metric.Min, metric.Max, metric.UpdatedAt
+	r.metrics[metric.UserID][metric.ID] = m
+	return true, nil
+}
+
+func (r *memoryMetricRepository) Delete(userID, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.metrics[userID][id]; !ok {
+		return false, nil
+	}
+	for _, values := range r.values {
+		delete(values, id)
+	}
+	delete(r.metrics[userID], id)
+	return true, nil
+}
+
+func (r *memoryMetricRepository) SetDiaryValues(diaryID string, values map[string]*float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byMetric, ok := r.values[diaryID]
+	if !ok {
+		byMetric = map[string]float64{}
+		r.values[diaryID] = byMetric
+	}
+	for metricID, value := range values {
+		if value == nil {
+			delete(byMetric, metricID)
+			continue
+		}
+		byMetric[metricID] = *value
+	}
+	return nil
+}
+
+func (r *memoryMetricRepository) ListForDiaries(diaryIDs []string) (map[string]map[string]float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]map[string]float64, len(diaryIDs))
+	for _, diaryID := range diaryIDs {
+		if len(r.values[diaryID]) == 0 {
+			continue
+		}
+		result[diaryID] = map[string]float64{}
+		for metricID, value := range r.values[diaryID] {
+			result[diaryID][metricID] = value
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryMetricRepository) ListValues(userID, startDate, endDate string) (map[string]map[string]float64, error) {
+	diaries, err := r.diaries.GetRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(diaries))
+	for i, d := range diaries {
+		ids[i] = d.ID
+	}
+	byDiary, err := r.ListForDiaries(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]map[string]float64{}
+	for _, d := range diaries {
+		if values, ok := byDiary[d.ID]; ok {
+			result[d.Date] = values
+		}
+	}
+	return result, nil
+}
+
+type memorySearchDoc struct {
+	userID string
+	body   string
+	length int
+	terms  map[string]int
+}
+
+type memorySearchIndexRepository struct {
+	mu sync.RWMutex
+	// diary_id -> 索引
+	docs map[string]memorySearchDoc
+	// diaries は日記の参照に使う（索引のロックを持ったまま日記のロックは取らない）
+	diaries *memoryDiaryRepository
+}
+
+func (r *memorySearchIndexRepository) removeDiary(diaryID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.docs, diaryID)
+}
+
+func (r *memorySearchIndexRepository) Index(userID, diaryID, body string, length int, terms map[string]int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := make(map[string]int, len(terms))
+	for term, tf := range terms {
+		copied[term] = tf
+	}
+	r.docs[diaryID] = memorySearchDoc{userID: userID, body: body, length: length, terms: copied}
+	return nil
+}
+
+func (r *memorySearchIndexRepository) Unindexed(limit int) ([]model.Diary, error) {
+	r.mu.RLock()
+	indexed := make(map[string]bool, len(r.docs))
+	for id := range r.docs {
+		indexed[id] = true
+	}
+	r.mu.RUnlock()
+
+	r.diaries.mu.RLock()
+	defer r.diaries.mu.RUnlock()
+
+	var diaries []model.Diary
+	for _, byDate := range r.diaries.diaries {
+		for _, d := range byDate {
+			if !indexed[d.ID] && len(diaries) < limit {
+				diaries = append(diaries, d)
+			}
+		}
+	}
+	return diaries, nil
+}
+
+// matched は q に一致する日記を関連度の高い順（同じなら日付の降順）にすべて返す
+func (r *memorySearchIndexRepository) matched(userID string, q SearchQuery) []SearchMatch {
+	r.mu.RLock()
+	stats := r.stats(userID, q.Terms)
+	scores := map[string]float64{}
+	for id, doc := range r.docs {
+		if doc.userID == userID && doc.matches(q) {
+			scores[id] = stats.bm25(q.Terms, doc.terms, doc.length)
+		}
+	}
+	r.mu.RUnlock()
+
+	var matches []SearchMatch
+	all := r.diaries.filtered(userID, q.Filter)
+	for i := len(all) - 1; i >= 0; i-- {
+		if score, ok := scores[all[i].ID]; ok {
+			matches = append(matches, SearchMatch{Diary: all[i], Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+func (r *memorySearchIndexRepository) Search(userID string, q SearchQuery, limit, offset int) ([]SearchMatch, error) {
+	matches := r.matched(userID, q)
+	if offset >= len(matches) {
+		return nil, nil
+	}
+	return matches[offset:min(len(matches), offset+limit)], nil
+}
+
+func (r *memorySearchIndexRepository) Count(userID string, q SearchQuery) (int, error) {
+	return len(r.matched(userID, q)), nil
+}
+
+// matches は索引語と語句をすべて含むかどうか
+func (doc memorySearchDoc) matches(q SearchQuery) bool {
+	for _, term := range q.Terms {
+		if doc.terms[term] == 0 {
+			return false
+		}
+	}
+	for _, phrase := range q.Phrases {
+		if !strings.Contains(doc.body, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+// stats はユーザーの索引全体の統計を返す。呼び出し側で r.mu を取っておく
+func (r *memorySearchIndexRepository) stats(userID string, terms []string) *searchStats {
+	stats := &searchStats{documentFrequency: make(map[string]int, len(terms))}
+	totalLength := 0
+	for _, doc := range r.docs {
+		if doc.userID != userID {
+			continue
+		}
+		stats.documents++
+		totalLength += doc.length
+		for _, term := range terms {
+			if doc.terms[term] > 0 {
+				stats.documentFrequency[term]++
+			}
+		}
+	}
+	if stats.documents > 0 {
+		stats.averageLength = float64(totalLength) / float64(stats.documents)
+	}
+	return stats
+}
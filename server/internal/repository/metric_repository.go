@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+type sqlMetricRepository struct {
+	db *sqlDB
+	d  dialect
+}
+
+const metricColumns = "id, user_id, name, type, unit, min_value, max_value, created_at, updated_at"
+
+func scanMetric(row interface{ Scan(...interface{}) error }, m *model.Metric) error {
+	var min, max sql.NullFloat64
+	if err := row.Scan(&m.ID, &m.UserID, &m.Name, &m.Type, &m.Unit, &min, &max,
+		timeValue{&m.CreatedAt}, timeValue{&m.UpdatedAt}); err != nil {
+		return err
+	}
+	if min.Valid {
+		m.Min = &min.Float64
+	}
+	if max.Valid {
+		m.Max = &max.Float64
+	}
+	return nil
+}
+
+func (r *sqlMetricRepository) List(userID string) ([]model.Metric, error) {
+	rows, err := r.db.Query("SELECT "+metricColumns+" FROM metrics WHERE user_id = ? ORDER BY created_at, name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var metrics []model.Metric
+	for rows.Next() {
+		var m model.Metric
+		if err := scanMetric(rows, &m); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+
+	return metrics, rows.Err()
+}
+
+func (r *sqlMetricRepository) GetByID(userID, id string) (*model.Metric, error) {
+	metric := &model.Metric{}
+	err := scanMetric(r.db.QueryRow("SELECT "+metricColumns+" FROM metrics WHERE user_id = ? AND id = ?", userID, id), metric)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return metric, nil
+}
+
+func (r *sqlMetricRepository) Create(metric *model.Metric) error {
+	_, err := r.db.Exec("INSERT INTO metrics ("+metricColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		metric.ID, metric.UserID, metric.Name, metric.Type, metric.Unit, metric.Min, metric.Max, metric.CreatedAt, metric.UpdatedAt)
+	if r.d.isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *sqlMetricRepository) Update(metric *model.Metric) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE metrics
+		SET name = ?, unit = ?, min_value = ?, max_value = ?, updated_at = ?
+		WHERE user_id = ? AND id = ?
+	`, metric.Name, metric.Unit, metric.Min, metric.Max, metric.UpdatedAt, metric.UserID, metric.ID)
+	if err != nil {
+		if r.d.isUniqueViolation(err) {
+			return false, ErrDuplicate
+		}
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *sqlMetricRepository) Delete(userID, id string) (bool, error) {
+	// 日記の値は外部キーの ON DELETE CASCADE で削除される
+	result, err := r.db.Exec("DELETE FROM metrics WHERE user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *sqlMetricRepository) SetDiaryValues(diaryID string, values map[string]*float64) error {
+	return r.db.inTx(func(db *sqlDB) error {
+		for metricID, value := range values {
+			if _, err := db.Exec("DELETE FROM diary_metric_values WHERE diary_id = ? AND metric_id = ?", diaryID, metricID); err != nil {
+				return err
+			}
+			if value == nil {
+				continue
+			}
+			_, err := db.Exec("INSERT INTO diary_metric_values (diary_id, metric_id, value) VALUES (?, ?, ?)", diaryID, metricID, *value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sqlMetricRepository) ListForDiaries(diaryIDs []string) (map[string]map[string]float64, error) {
+	result := make(map[string]map[string]float64, len(diaryIDs))
+	if len(diaryIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(diaryIDs)), ", ")
+	args := make([]interface{}, len(diaryIDs))
+	for i, id := range diaryIDs {
+		args[i] = id
+	}
+
+	rows, err := r.db.Query(`
+		SELECT diary_id, metric_id, value
+		FROM diary_metric_values
+		WHERE diary_id IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectValues(rows, result, false)
+}
+
+func (r *sqlMetricRepository) ListValues(userID, startDate, endDate string) (map[string]map[string]float64, error) {
+	rows, err := r.db.Query(`
+		SELECT d.date, v.metric_id, v.value
+		FROM diary_metric_values v
+		JOIN diaries d ON d.id = v.diary_id
+		WHERE d.user_id = ? AND d.date >= ? AND d.date <= ?
+	`, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectValues(rows, map[string]map[string]float64{}, true)
+}
+
+// collectValues はキー（日記ID、byDate が true の場合は日付）・記録項目の ID・値の行を result にまとめる
+func collectValues(rows *sql.Rows, result map[string]map[string]float64, byDate bool) (map[string]map[string]float64, error) {
+	for rows.Next() {
+		var key, metricID string
+		var value float64
+		var keyDest interface{} = &key
+		if byDate {
+			keyDest = dateValue{&key}
+		}
+		if err := rows.Scan(keyDest, &metricID, &value); err != nil {
+			return nil, err
+		}
+		if result[key] == nil {
+			result[key] = map[string]float64{}
+		}
+		result[key][metricID] = value
+	}
+
+	return result, rows.Err()
+}
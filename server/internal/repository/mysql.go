@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var mysqlDialect = dialect{
+	name: "mysql",
+	insertIgnore: func(query string) string {
+		return strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+	},
+	isUniqueViolation: func(err error) bool {
+		var mysqlErr *mysql.MySQLError
+		return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+	},
+}
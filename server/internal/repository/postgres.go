@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var postgresDialect = dialect{
+	name:   "postgres",
+	rebind: rebindDollar,
+	insertIgnore: func(query string) string {
+		return query + " ON CONFLICT DO NOTHING"
+	},
+	isUniqueViolation: func(err error) bool {
+		var pqErr *pq.Error
+		return errors.As(err, &pqErr) && pqErr.Code == "23505"
+	},
+}
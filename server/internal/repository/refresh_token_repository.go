@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+type sqlRefreshTokenRepository struct {
+	db *sqlDB
+	d  dialect
+}
+
+func (r *sqlRefreshTokenRepository) Create(token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, user_agent, ip_address, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(query, token.ID, token.UserID, token.FamilyID,
+		token.UserAgent, token.IPAddress, token.ExpiresAt, token.CreatedAt)
+	return err
+}
+
+func (r *sqlRefreshTokenRepository) GetByID(userID, id string) (*model.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, user_agent, ip_address, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE id = ? AND user_id = ?
+	`
+
+	t := &model.RefreshToken{}
+	err := r.db.QueryRow(query, id, userID).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.UserAgent, &t.IPAddress,
+		timeValue{&t.ExpiresAt}, nullTimeValue{&t.UsedAt}, nullTimeValue{&t.RevokedAt}, timeValue{&t.CreatedAt},
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r *sqlRefreshTokenRepository) MarkUsed(userID, id string, now time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE refresh_tokens SET used_at = ?
+		WHERE id = ? AND user_id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?
+	`, now, id, userID, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *sqlRefreshTokenRepository) RevokeFamily(userID, familyID string, now time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE refresh_tokens SET revoked_at = ?
+		WHERE user_id = ? AND family_id = ? AND revoked_at IS NULL
+	`, now, userID, familyID)
+	return err
+}
+
+func (r *sqlRefreshTokenRepository) FamilyActive(userID, familyID string, now time.Time) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM refresh_tokens
+		WHERE user_id = ? AND family_id = ? AND revoked_at IS NULL AND expires_at > ?
+	`, userID, familyID, now).Scan(&count)
+	return count > 0, err
+}
+
+func (r *sqlRefreshTokenRepository) ListSessions(userID string, now time.Time) ([]model.Session, error) {
+	query := `
+		SELECT t.family_id, t.user_agent, t.ip_address,
+			(SELECT MIN(f.created_at) FROM refresh_tokens f WHERE f.family_id = t.family_id),
+			t.created_at, t.expires_at
+		FROM refresh_tokens t
+		WHERE t.user_id = ? AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > ?
+		ORDER BY t.created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		var s model.Session
+		err := rows.Scan(
+			&s.ID, &s.UserAgent, &s.IPAddress,
+			timeValue{&s.CreatedAt}, timeValue{&s.LastUsedAt}, timeValue{&s.ExpiresAt},
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/config"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+var (
+	// ErrDuplicate は一意制約（ユーザー×日付など）に違反した場合に返される
+	ErrDuplicate = errors.New("duplicate entry")
+)
+
+type DiaryRepository interface {
+	Create(diary *model.Diary) error
+	// GetByDate は該当する日記がない場合 nil, nil を返す
+	GetByDate(userID, date string) (*model.Diary, error)
+	// GetAll は filter に一致する日記を order の順で limit 件返す
+	GetAll(userID string, filter DiaryFilter, order DiaryOrder, limit, offset int) ([]model.Diary, error)
+	// Count は filter に一致する日記の件数を返す
+	Count(userID string, filter DiaryFilter) (int, error)
+	// GetRange は期間内の日記を日付の昇順ですべて返す（集計用）
+	GetRange(userID, startDate, endDate string) ([]model.Diary, error)
+	// Update は diary の内容で既存の日記を上書きする
+	Update(diary *model.Diary) error
+	// Delete は日記を削除し、削除したかどうかを返す
+	Delete(userID, date string) (bool, error)
+
+	// GetCalendarEntries は filter に一致する日記の日付・評価・進捗・メモの有無を日付順に返す（本文は取得しない）
+	GetCalendarEntries(userID string, filter DiaryFilter) ([]model.CalendarEntry, error)
+	// GetStatistics は期間内の件数・平均評価・分布を集計する
+	GetStatistics(userID, startDate, endDate string) (*model.Statistics, error)
+	// GetDates は日記が記録されている日付を昇順で返す
+	GetDates(userID string) ([]string, error)
+}
+
+// DiaryFilter は日記の一覧・件数・カレンダーの絞り込み条件（空の項目は条件にしない）
+type DiaryFilter struct {
+	StartDate string
+	EndDate   string
+	// Tags のすべて（MatchAnyTag が true の場合はいずれか）が付いた日記に絞る
+	Tags        []string
+	MatchAnyTag bool
+	// MinRating / MaxRating は評価の範囲（0 は制限なし）
+	MinRating int
+	MaxRating int
+	// Expr は絞り込み式（nil は条件なし）
+	Expr *FilterExpr
+}
+
+// FilterField は絞り込み式で比べる日記の項目
+type FilterField string
+
+const (
+	FilterDate       FilterField = "date"
+	FilterRating     FilterField = "rating"
+	FilterProgress   FilterField = "progress"
+	FilterWakeUpTime FilterField = "wake_up_time"
+	FilterSleepTime  FilterField = "sleep_time"
+	// FilterTag は = でタグが付いている、!= で付いていないことを表す
+	FilterTag FilterField = "tag"
+)
+
+// FilterOp は絞り込み式の論理演算子または比較演算子
+type FilterOp string
+
+const (
+	FilterAnd FilterOp = "AND"
+	FilterOr  FilterOp = "OR"
+	FilterNot FilterOp = "NOT"
+	FilterEq  FilterOp = "="
+	FilterNe  FilterOp = "!="
+	FilterLt  FilterOp = "<"
+	FilterLe  FilterOp = "<="
+	FilterGt  FilterOp = ">"
+	FilterGe  FilterOp = ">="
+)
+
+// FilterExpr は絞り込み式の節点。AND / OR / NOT は Operands（NOT は1つ）を、
+// 比較は Field・Op・Value（rating は int、それ以外は string）を使う
+type FilterExpr struct {
+	Op       FilterOp
+	Field    FilterField
+	Value    interface{}
+	Operands []FilterExpr
+}
+
+// DiaryOrder は日記一覧の並び順。同じ値の日記は日付で同じ向きに並べる
+type DiaryOrder struct {
+	// Field は date / rating / updated_at
+	Field     string
+	Ascending bool
+}
+
+type UserRepository interface {
+	// Create は email または username が重複する場合 ErrDuplicate を返す
+	Create(user *model.User) error
+	// EnsureExists はユーザーが存在しない場合のみ作成する
+	EnsureExists(user *model.User) error
+	// GetByEmail は該当するユーザーがいない場合 nil, nil を返す
+	GetByEmail(email string) (*model.User, error)
+	ExistsByEmail(email string) (bool, error)
+	ExistsByUsername(username string) (bool, error)
+
+	// GetSettings は該当するユーザーがいない場合 nil, nil を返す
+	GetSettings(userID string) (*model.UserSettings, error)
+	// UpdateSettings は設定を上書きし、ユーザーが存在したかどうかを返す
+	UpdateSettings(userID string, settings *model.UserSettings) (bool, error)
+}
+
+type RefreshTokenRepository interface {
+	Create(token *model.RefreshToken) error
+	// GetByID は該当するトークンがない場合 nil, nil を返す
+	GetByID(userID, id string) (*model.RefreshToken, error)
+	// MarkUsed は未使用・未失効・有効期限内のトークンのみ使用済みにし、更新したかどうかを返す
+	MarkUsed(userID, id string, now time.Time) (bool, error)
+	RevokeFamily(userID, familyID string, now time.Time) error
+	// FamilyActive は失効も期限切れもしていないトークンがファミリー内に残っているかを返す
+	FamilyActive(userID, familyID string, now time.Time) (bool, error)
+	// ListSessions は有効なセッション（ファミリーごとの最新トークン）を新しい順に返す
+	ListSessions(userID string, now time.Time) ([]model.Session, error)
+}
+
+type StreakFreezeRepository interface {
+	// Create は同じ日付のフリーズが既にある場合 ErrDuplicate を返す
+	Create(userID, date string, createdAt time.Time) error
+	// Delete はフリーズを削除し、削除したかどうかを返す
+	Delete(userID, date string) (bool, error)
+	// List は期間内のフリーズ日を昇順で返す（空文字は無制限）
+	List(userID, startDate, endDate string) ([]string, error)
+}
+
+type TagRepository interface {
+	// List はタグを名前順に日記の数とともに返す
+	List(userID string) ([]model.Tag, error)
+	// GetByID は該当するタグがない場合 nil, nil を返す
+	GetByID(userID, id string) (*model.Tag, error)
+	// SetDiaryTags は日記のタグを names に置き換える。存在しないタグは作成する
+	SetDiaryTags(userID, diaryID string, names []string) error
+	// ListForDiaries は日記IDごとのタグ名（名前順）を返す
+	ListForDiaries(diaryIDs []string) (map[string][]string, error)
+	// Rename は名前を変更し、変更したかどうかを返す。同じ名前のタグがある場合 ErrDuplicate を返す
+	Rename(userID, id, name string) (bool, error)
+	// Merge は sourceID の付いた日記に targetID を付け、sourceID を削除する
+	Merge(userID, sourceID, targetID string) error
+	// Delete はタグを削除し（日記からも外れる）、削除したかどうかを返す
+	Delete(userID, id string) (bool, error)
+	// Stats は期間内のタグごとの記録数と平均評価を記録数の多い順に返す
+	Stats(userID, startDate, endDate string) ([]model.TagStat, error)
+}
+
+type MetricRepository interface {
+	// List は記録項目を作成順に返す
+	List(userID string) ([]model.Metric, error)
+	// GetByID は該当する記録項目がない場合 nil, nil を返す
+	GetByID(userID, id string) (*model.Metric, error)
+	// Create は同じ名前の記録項目がある場合 ErrDuplicate を返す
+	Create(metric *model.Metric) error
+	// Update は名前・単位・範囲を上書きし、更新したかどうかを返す。同じ名前の記録項目がある場合 ErrDuplicate を返す
+	Update(metric *model.Metric) (bool, error)
+	// Delete は記録項目とその値を削除し、削除したかどうかを返す
+	Delete(userID, id string) (bool, error)
+	// SetDiaryValues は日記の値を記録項目の ID ごとに設定する（nil の項目は値を削除する）
+	SetDiaryValues(diaryID string, values map[string]*float64) error
+	// ListForDiaries は日記IDごとの値（記録項目の ID -> 値）を返す
+	ListForDiaries(diaryIDs []string) (map[string]map[string]float64, error)
+	// ListValues は期間内の日付ごとの値（記録項目の ID -> 値）を返す
+	ListValues(userID, startDate, endDate string) (map[string]map[string]float64, error)
+}
+
+// SearchIndexRepository はメモの全文検索用の索引
+type SearchIndexRepository interface {
+	// Index は日記の正規化したメモ・索引語（語 -> 出現回数）・語数を置き換える
+	Index(userID, diaryID, body string, length int, terms map[string]int) error
+	// Unindexed は索引のない日記を最大 limit 件返す（全ユーザーが対象。索引の作成用）
+	Unindexed(limit int) ([]model.Diary, error)
+	// Search は q に一致する日記を関連度の高い順（同じなら日付の降順）に offset から limit 件返す
+	Search(userID string, q SearchQuery, limit, offset int) ([]SearchMatch, error)
+	// Count は q に一致する日記の件数を返す
+	Count(userID string, q SearchQuery) (int, error)
+}
+
+// SearchQuery は全文検索の条件
+type SearchQuery struct {
+	// Terms は日記がすべて含む索引語（順位付けにも使う）
+	Terms []string
+	// Phrases は正規化したメモに文字列として含まれる語
+	Phrases []string
+	Filter  DiaryFilter
+}
+
+// SearchMatch は検索に一致した日記と、Okapi BM25 による関連度（小数第3位まで）
+type SearchMatch struct {
+	Diary model.Diary
+	Score float64
+}
+
+// Store はバックエンドごとのリポジトリをまとめたもの
+type Store struct {
+	Diaries       DiaryRepository
+	Users         UserRepository
+	RefreshTokens RefreshTokenRepository
+	StreakFreezes StreakFreezeRepository
+	Tags          TagRepository
+	Metrics       MetricRepository
+	SearchIndex   SearchIndexRepository
+
+	// db は SQL バックエンドの接続（インメモリの場合は nil）
+	db     *sql.DB
+	driver string
+	withTx func(fn func(Repos) error) error
+}
+
+// Repos はトランザクション内で使う日記関連のリポジトリ
+type Repos struct {
+	Diaries     DiaryRepository
+	Tags        TagRepository
+	Metrics     MetricRepository
+	SearchIndex SearchIndexRepository
+}
+
+// Transactor は日記関連の複数の書き込みを1つのトランザクションで実行する
+type Transactor interface {
+	// WithTx は fn に渡したリポジトリでの書き込みを1つのトランザクションで実行し、
+	// fn がエラーを返した場合はロールバックする
+	WithTx(fn func(Repos) error) error
+}
+
+func (s *Store) WithTx(fn func(Repos) error) error {
+	return s.withTx(fn)
+}
+
+// DB は SQL バックエンドの接続を返す。インメモリの場合は nil を返す。
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Driver は mysql / postgres / sqlite / memory のいずれかを返す
+func (s *Store) Driver() string {
+	return s.driver
+}
+
+func (s *Store) Ping() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Ping()
+}
+
+func (s *Store) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Open は cfg.Driver に応じたバックエンドに接続する。
+// スキーマは migrate パッケージで管理する。
+func Open(cfg config.DatabaseConfig) (*Store, error) {
+	switch cfg.Driver {
+	case "mysql":
+		return openSQL("mysql", cfg.DSN(), mysqlDialect)
+	case "postgres":
+		return openSQL("postgres", cfg.DSN(), postgresDialect)
+	case "sqlite":
+		return openSQL("sqlite", sqliteDSN(cfg.Path), sqliteDialect)
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER: %q", cfg.Driver)
+	}
+}
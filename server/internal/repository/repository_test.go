@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+const testUserID = "test-user"
+
+// newTestStore は一時ディレクトリの SQLite にスキーマを作成した Store を返す。
+// migrate パッケージはこのパッケージに依存するため、マイグレーションのファイルを直接実行する
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := openSQL("sqlite", sqliteDSN(filepath.Join(t.TempDir(), "test.db")), sqliteDialect)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	files, err := filepath.Glob(filepath.Join("..", "migrate", "migrations", "sqlite", "*.up.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations: %v", err)
+	}
+	slices.Sort(files)
+	for _, file := range files {
+		script, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.db.Exec(string(script)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(file), err)
+		}
+	}
+
+	now := time.Now().UTC()
+	user := &model.User{ID: testUserID, Username: "tester", Email: "tester@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := store.Users.Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return store
+}
+
+// createDiary は date の日記を作成し、その ID を返す
+func createDiary(t *testing.T, store *Store, date string, rating int, progress, wakeUpTime, sleepTime string) string {
+	t.Helper()
+	now := time.Now().UTC()
+	diary := &model.Diary{
+		ID: uuid.New().String(), UserID: testUserID, Date: date, Rating: rating, Progress: progress,
+		WakeUpTime: wakeUpTime, SleepTime: sleepTime, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := store.Diaries.Create(diary); err != nil {
+		t.Fatalf("create %s: %v", date, err)
+	}
+	return diary.ID
+}
+
+// diaryDates は日記の日付を順に返す
+func diaryDates(diaries []model.Diary) []string {
+	dates := make([]string, len(diaries))
+	for i, d := range diaries {
+		dates[i] = d.Date
+	}
+	return dates
+}
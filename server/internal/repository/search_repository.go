@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+const (
+	// searchInsertBatch は索引語を1つの INSERT でまとめて登録する件数
+	searchInsertBatch = 100
+
+	// BM25 のパラメータ
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// searchStats は順位付けに使う索引全体の統計
+type searchStats struct {
+	documents         int
+	averageLength     float64
+	documentFrequency map[string]int
+}
+
+// idf は語を含む日記の数から BM25 の IDF を求める
+func (s *searchStats) idf(term string) float64 {
+	df := float64(s.documentFrequency[term])
+	return math.Log(1 + (float64(s.documents)-df+0.5)/(df+0.5))
+}
+
+// avgLength は平均語数を返す（索引が空の場合は 1）
+func (s *searchStats) avgLength() float64 {
+	if s.averageLength == 0 {
+		return 1
+	}
+	return s.averageLength
+}
+
+// bm25 は語ごとの出現回数 tf と語数 length から関連度を求める（小数第3位まで）
+func (s *searchStats) bm25(terms []string, tf map[string]int, length int) float64 {
+	score := 0.0
+	for _, term := range terms {
+		f := float64(tf[term])
+		if f == 0 {
+			continue
+		}
+		score += s.idf(term) * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*float64(length)/s.avgLength()))
+	}
+	return math.Round(score*1000) / 1000
+}
+
+type sqlSearchIndexRepository struct {
+	db *sqlDB
+	d  dialect
+}
+
+func (r *sqlSearchIndexRepository) Index(userID, diaryID, body string, length int, terms map[string]int) error {
+	return r.db.inTx(func(db *sqlDB) error {
+		if _, err := db.Exec("DELETE FROM diary_search_terms WHERE diary_id = ?", diaryID); err != nil {
+			return err
+		}
+		if _, err := db.Exec("DELETE FROM diary_search_docs WHERE diary_id = ?", diaryID); err != nil {
+			return err
+		}
+		if _, err := db.Exec("INSERT INTO diary_search_docs (diary_id, user_id, length, body) VALUES (?, ?, ?, ?)", diaryID, userID, length, body); err != nil {
+			return err
+		}
+
+		var rows []string
+		var args []interface{}
+		flush := func() error {
+			if len(rows) == 0 {
+				return nil
+			}
+			_, err := db.Exec("INSERT INTO diary_search_terms (diary_id, user_id, term, tf) VALUES "+strings.Join(rows, ", "), args...)
+			rows, args = rows[:0], args[:0]
+			return err
+		}
+		for term, tf := range terms {
+			rows = append(rows, "(?, ?, ?, ?)")
+			args = append(args, diaryID, userID, term, tf)
+			if len(rows) == searchInsertBatch {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return flush()
+	})
+}
+
+func (r *sqlSearchIndexRepository) Unindexed(limit int) ([]model.Diary, error) {
+	rows, err := r.db.Query(`
+		SELECT `+diaryColumns+`
+		FROM diaries
+		WHERE id NOT IN (SELECT diary_id FROM diary_search_docs)
+		ORDER BY id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDiaries(rows)
+}
+
+// likePattern は s をそのまま含む文字列に一致する LIKE のパターンを返す（エスケープ文字は !）
+func likePattern(s string) string {
+	s = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+	return "%" + s + "%"
+}
+
+// matchQuery は q のすべての索引語と語句を含む日記の diary_id を返す副問い合わせと引数を返す。
+// score が空でない場合は、その式を score 列として加える（scoreArgs は式の引数）
+func matchQuery(userID string, q SearchQuery, score string, scoreArgs []interface{}) (string, []interface{}) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(q.Terms)), ", ")
+	args := append([]interface{}{}, scoreArgs...)
+	args = append(args, userID)
+	for _, term := range q.Terms {
+		args = append(args, term)
+	}
+
+	var phrases strings.Builder
+	for _, phrase := range q.Phrases {
+		phrases.WriteString(" AND s.body LIKE ? ESCAPE '!'")
+		args = append(args, likePattern(phrase))
+	}
+	args = append(args, len(q.Terms))
+
+	if score != "" {
+		score = ", " + score + " AS score"
+	}
+	return `
+		SELECT t.diary_id` + score + `
+		FROM diary_search_terms t
+		JOIN diary_search_docs s ON s.diary_id = t.diary_id
+		WHERE t.user_id = ? AND t.term IN (` + placeholders + `)` + phrases.String() + `
+		GROUP BY t.diary_id
+		HAVING COUNT(*) = ?
+	`, args
+}
+
+// scoredRow は日記の列に続く score 列を読み取る
+type scoredRow struct {
+	rows  *sql.Rows
+	score *float64
+}
+
+func (r scoredRow) Scan(dest ...interface{}) error {
+	return r.rows.Scan(append(dest, r.score)...)
+}
+
+func (r *sqlSearchIndexRepository) Search(userID string, q SearchQuery, limit, offset int) ([]SearchMatch, error) {
+	stats, err := r.stats(userID, q.Terms)
+	if err != nil {
+		return nil, err
+	}
+	clause, filterArgs, err := filterClause(userID, q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 語ごとの IDF と平均語数を引数で渡し、BM25 を SQL で集計する。
+	// PostgreSQL でも数値として扱われるよう、数値の引数は CAST する
+	var weight strings.Builder
+	var scoreArgs []interface{}
+	weight.WriteString("CASE t.term")
+	for _, term := range q.Terms {
+		weight.WriteString(" WHEN ? THEN CAST(? AS DECIMAL(20, 10))")
+		scoreArgs = append(scoreArgs, term, stats.idf(term))
+	}
+	weight.WriteString(" END")
+	scoreArgs = append(scoreArgs, stats.avgLength())
+	score := fmt.Sprintf("ROUND(SUM(%s * t.tf * %g / (t.tf + %g * (%g + %g * s.length / CAST(? AS DECIMAL(20, 10))))), 3)",
+		weight.String(), bm25K1+1, bm25K1, 1-bm25B, bm25B)
+
+	matched, args := matchQuery(userID, q, score, scoreArgs)
+	args = append(args, userID)
+	args = append(args, filterArgs...)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(`
+		SELECT `+diaryColumns+`, m.score
+		FROM diaries
+		JOIN (`+matched+`) m ON m.diary_id = diaries.id
+		WHERE user_id = ?`+clause+`
+		ORDER BY m.score DESC, date DESC
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []SearchMatch
+	for rows.Next() {
+		var m SearchMatch
+		if err := scanDiary(scoredRow{rows: rows, score: &m.Score}, &m.Diary); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+func (r *sqlSearchIndexRepository) Count(userID string, q SearchQuery) (int, error) {
+	clause, filterArgs, err := filterClause(userID, q.Filter)
+	if err != nil {
+		return 0, err
+	}
+
+	matched, args := matchQuery(userID, q, "", nil)
+	args = append(args, userID)
+	args = append(args, filterArgs...)
+
+	var count int
+	err = r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM diaries
+		JOIN (`+matched+`) m ON m.diary_id = diaries.id
+		WHERE user_id = ?`+clause, args...).Scan(&count)
+	return count, err
+}
+
+func (r *sqlSearchIndexRepository) stats(userID string, terms []string) (*searchStats, error) {
+	stats := &searchStats{documentFrequency: make(map[string]int, len(terms))}
+
+	var avg sql.NullFloat64
+	if err := r.db.QueryRow("SELECT COUNT(*), AVG(length) FROM diary_search_docs WHERE user_id = ?", userID).
+		Scan(&stats.documents, &avg); err != nil {
+		return nil, err
+	}
+	stats.averageLength = avg.Float64
+	if len(terms) == 0 {
+		return stats, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(terms)), ", ")
+	args := []interface{}{userID}
+	for _, term := range terms {
+		args = append(args, term)
+	}
+	rows, err := r.db.Query(`
+		SELECT term, COUNT(*)
+		FROM diary_search_terms
+		WHERE user_id = ? AND term IN (`+placeholders+`)
+		GROUP BY term
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var term string
+		var n int
+		if err := rows.Scan(&term, &n); err != nil {
+			return nil, err
+		}
+		stats.documentFrequency[term] = n
+	}
+
+	return stats, rows.Err()
+}
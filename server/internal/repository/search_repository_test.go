@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLikePattern(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{s: "散歩", want: "%散歩%"},
+		{s: "100%", want: "%100!%%"},
+		{s: "a_b", want: "%a!_b%"},
+		{s: "!", want: "%!!%"},
+	}
+
+	for _, tt := range tests {
+		if got := likePattern(tt.s); got != tt.want {
+			t.Errorf("likePattern(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	store := newTestStore(t)
+	docs := []struct {
+		date, body string
+		rating     int
+		terms      map[string]int
+	}{
+		{date: "2026-01-01", body: "go go run", rating: 3, terms: map[string]int{"go": 2, "run": 1}},
+		{date: "2026-01-02", body: "go walk walk walk walk", rating: 5, terms: map[string]int{"go": 1, "walk": 4}},
+		{date: "2026-01-03", body: "walk", rating: 4, terms: map[string]int{"walk": 1}},
+		{date: "2026-01-04", body: "100% go_lang", rating: 2, terms: map[string]int{"100": 1, "go": 1, "lang": 1}},
+	}
+	for _, doc := range docs {
+		id := createDiary(t, store, doc.date, doc.rating, "A", "07:00", "23:00")
+		length := 0
+		for _, tf := range doc.terms {
+			length += tf
+		}
+		if err := store.SearchIndex.Index(testUserID, id, doc.body, length, doc.terms); err != nil {
+			t.Fatalf("index: %v", err)
+		}
+	}
+
+	// SQL で集計した関連度は Go で求めたものと一致する
+	stats := &searchStats{documents: 4, averageLength: 3, documentFrequency: map[string]int{"go": 3, "walk": 2}}
+	tests := []struct {
+		name       string
+		q          SearchQuery
+		want       []string
+		wantScores []float64
+	}{
+		{
+			name: "more occurrences rank first",
+			q:    SearchQuery{Terms: []string{"go"}, Phrases: []string{"go"}},
+			want: []string{"2026-01-01", "2026-01-04", "2026-01-02"},
+			wantScores: []float64{
+				stats.bm25([]string{"go"}, map[string]int{"go": 2}, 3),
+				stats.bm25([]string{"go"}, map[string]int{"go": 1}, 3),
+				stats.bm25([]string{"go"}, map[string]int{"go": 1}, 5),
+			},
+		},
+		{
+			name:       "all terms",
+			q:          SearchQuery{Terms: []string{"go", "walk"}},
+			want:       []string{"2026-01-02"},
+			wantScores: []float64{stats.bm25([]string{"go", "walk"}, map[string]int{"go": 1, "walk": 4}, 5)},
+		},
+		{name: "phrase", q: SearchQuery{Terms: []string{"go"}, Phrases: []string{"go go"}}, want: []string{"2026-01-01"}},
+		{name: "percent is not a wildcard", q: SearchQuery{Terms: []string{"go"}, Phrases: []string{"0%"}}, want: []string{"2026-01-04"}},
+		{name: "underscore is not a wildcard", q: SearchQuery{Terms: []string{"go"}, Phrases: []string{"o_l"}}, want: []string{"2026-01-04"}},
+		{name: "escaped character", q: SearchQuery{Terms: []string{"go"}, Phrases: []string{"go lang"}}, want: nil},
+		{name: "filter", q: SearchQuery{Terms: []string{"walk"}, Filter: DiaryFilter{MinRating: 5}}, want: []string{"2026-01-02"}},
+		{name: "unknown term", q: SearchQuery{Terms: []string{"swim"}}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := store.SearchIndex.Search(testUserID, tt.q, 10, 0)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			var got []string
+			var scores []float64
+			for _, m := range matches {
+				got = append(got, m.Diary.Date)
+				scores = append(scores, m.Score)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dates = %v, want %v", got, tt.want)
+			}
+			if tt.wantScores != nil && !slices.Equal(scores, tt.wantScores) {
+				t.Errorf("scores = %v, want %v", scores, tt.wantScores)
+			}
+
+			count, err := store.SearchIndex.Count(testUserID, tt.q)
+			if err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("count = %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+
+	page, err := store.SearchIndex.Search(testUserID, SearchQuery{Terms: []string{"go"}}, 1, 1)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := len(page); got != 1 || page[0].Diary.Date != "2026-01-04" {
+		t.Errorf("second page = %+v", page)
+	}
+}
+
+func TestIndexReplaces(t *testing.T) {
+	store := newTestStore(t)
+	id := createDiary(t, store, "2026-01-01", 3, "A", "07:00", "23:00")
+	if err := store.SearchIndex.Index(testUserID, id, "go", 1, map[string]int{"go": 1}); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if unindexed, err := store.SearchIndex.Unindexed(10); err != nil || len(unindexed) != 0 {
+		t.Errorf("unindexed = %d, error = %v", len(unindexed), err)
+	}
+
+	// 索引語の一括登録の上限を超える語数
+	terms := map[string]int{}
+	for i := range searchInsertBatch + 5 {
+		terms[string(rune('a'+i%26))+string(rune('a'+i/26))] = 1
+	}
+	if err := store.SearchIndex.Index(testUserID, id, "many", len(terms), terms); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+	if count, err := store.SearchIndex.Count(testUserID, SearchQuery{Terms: []string{"go"}}); err != nil || count != 0 {
+		t.Errorf("old term: count = %d, error = %v", count, err)
+	}
+	if count, err := store.SearchIndex.Count(testUserID, SearchQuery{Terms: []string{"ad"}}); err != nil || count != 1 {
+		t.Errorf("new term: count = %d, error = %v", count, err)
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import "time"
+
+type sqlStreakFreezeRepository struct {
+	db *sqlDB
+	d  dialect
+}
+
+func (r *sqlStreakFreezeRepository) Create(userID, date string, createdAt time.Time) error {
+	_, err := r.db.Exec("INSERT INTO streak_freezes (user_id, date, created_at) VALUES (?, ?, ?)", userID, date, createdAt)
+	if err != nil && r.d.isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *sqlStreakFreezeRepository) Delete(userID, date string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM streak_freezes WHERE user_id = ? AND date = ?", userID, date)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *sqlStreakFreezeRepository) List(userID, startDate, endDate string) ([]string, error) {
+	query := "SELECT date FROM streak_freezes WHERE user_id = ?"
+	args := []interface{}{userID}
+
+	if startDate != "" {
+		query += " AND date >= ?"
+		args = append(args, startDate)
+	}
+	if endDate != "" {
+		query += " AND date <= ?"
+		args = append(args, endDate)
+	}
+	query += " ORDER BY date"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []string
+	for rows.Next() {
+		var d string
+		if err := rows.Scan(dateValue{&d}); err != nil {
+			return nil, err
+		}
+		dates = append(dates, d)
+	}
+
+	return dates, rows.Err()
+}
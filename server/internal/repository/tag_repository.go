@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+type sqlTagRepository struct {
+	db *sqlDB
+	d  dialect
+}
+
+const tagQuery = `
+	SELECT t.id, t.name, t.created_at, COUNT(dt.diary_id)
+	FROM tags t
+	LEFT JOIN diary_tags dt ON dt.tag_id = t.id
+`
+
+func scanTag(row interface{ Scan(...interface{}) error }, t *model.Tag) error {
+	return row.Scan(&t.ID, &t.Name, timeValue{&t.CreatedAt}, &t.DiaryCount)
+}
+
+func (r *sqlTagRepository) List(userID string) ([]model.Tag, error) {
+	rows, err := r.db.Query(tagQuery+`
+		WHERE t.user_id = ?
+		GROUP BY t.id, t.name, t.created_at
+		ORDER BY t.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []model.Tag
+	for rows.Next() {
+		var t model.Tag
+		if err := scanTag(rows, &t); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *sqlTagRepository) GetByID(userID, id string) (*model.Tag, error) {
+	tag := &model.Tag{}
+	err := scanTag(r.db.QueryRow(tagQuery+`
+		WHERE t.user_id = ? AND t.id = ?
+		GROUP BY t.id, t.name, t.created_at
+	`, userID, id), tag)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *sqlTagRepository) SetDiaryTags(userID, diaryID string, names []string) error {
+	return r.db.inTx(func(db *sqlDB) error {
+		if _, err := db.Exec("DELETE FROM diary_tags WHERE diary_id = ?", diaryID); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			_, err := db.Exec(r.d.insertIgnore("INSERT INTO tags (id, user_id, name, created_at) VALUES (?, ?, ?, ?)"),
+				uuid.New().String(), userID, name, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+
+			var tagID string
+			if err := db.QueryRow("SELECT id FROM tags WHERE user_id = ? AND name = ?", userID, name).Scan(&tagID); err != nil {
+				return err
+			}
+			_, err = db.Exec(r.d.insertIgnore("INSERT INTO diary_tags (diary_id, tag_id) VALUES (?, ?)"), diaryID, tagID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sqlTagRepository) ListForDiaries(diaryIDs []string) (map[string][]string, error) {
+	result := make(map[string][]string, len(diaryIDs))
+	if len(diaryIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(diaryIDs)), ", ")
+	args := make([]interface{}, len(diaryIDs))
+	for i, id := range diaryIDs {
+		args[i] = id
+	}
+
+	rows, err := r.db.Query(`
+		SELECT dt.diary_id, t.name
+		FROM diary_tags dt
+		JOIN tags t ON t.id = dt.tag_id
+		WHERE dt.diary_id IN (`+placeholders+`)
+		ORDER BY t.name
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var diaryID, name string
+		if err := rows.Scan(&diaryID, &name); err != nil {
+			return nil, err
+		}
+		result[diaryID] = append(result[diaryID], name)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *sqlTagRepository) Rename(userID, id, name string) (bool, error) {
+	result, err := r.db.Exec("UPDATE tags SET name = ? WHERE user_id = ? AND id = ?", name, userID, id)
+	if err != nil {
+		if r.d.isUniqueViolation(err) {
+			return false, ErrDuplicate
+		}
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *sqlTagRepository) Merge(userID, sourceID, targetID string) error {
+	return r.db.inTx(func(db *sqlDB) error {
+		// 両方のタグが付いた日記は重複するため無視する。
+		// targetID は tags.id と比べて渡し、PostgreSQL でも uuid 型として扱われるようにする
+		_, err := db.Exec(r.d.insertIgnore(`
+			INSERT INTO diary_tags (diary_id, tag_id)
+			SELECT dt.diary_id, t.id
+			FROM diary_tags dt
+			JOIN tags t ON t.id = ? AND t.user_id = ?
+			WHERE dt.tag_id = ?
+		`), targetID, userID, sourceID)
+		if err != nil {
+			return err
+		}
+
+		// 日記への付与は外部キーの ON DELETE CASCADE で削除される
+		_, err = db.Exec("DELETE FROM tags WHERE user_id = ? AND id = ?", userID, sourceID)
+		return err
+	})
+}
+
+func (r *sqlTagRepository) Delete(userID, id string) (bool, error) {
+	// 日記への付与は外部キーの ON DELETE CASCADE で削除される
+	result, err := r.db.Exec("DELETE FROM tags WHERE user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *sqlTagRepository) Stats(userID, startDate, endDate string) ([]model.TagStat, error) {
+	rows, err := r.db.Query(`
+		SELECT t.name, COUNT(*), AVG(d.rating)
+		FROM diary_tags dt
+		JOIN tags t ON t.id = dt.tag_id
+		JOIN diaries d ON d.id = dt.diary_id
+		WHERE d.user_id = ? AND d.date >= ? AND d.date <= ?
+		GROUP BY t.name
+		ORDER BY COUNT(*) DESC, t.name
+	`, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []model.TagStat
+	for rows.Next() {
+		var s model.TagStat
+		if err := rows.Scan(&s.Name, &s.TotalEntries, &s.AverageRating); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// tagIDs はタグ名ごとの ID を返す
+func tagIDs(t *testing.T, store *Store) map[string]string {
+	t.Helper()
+	tags, err := store.Tags.List(testUserID)
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	ids := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		ids[tag.Name] = tag.ID
+	}
+	return ids
+}
+
+// tagCounts はタグ名ごとの日記の数を返す
+func tagCounts(t *testing.T, store *Store) map[string]int {
+	t.Helper()
+	tags, err := store.Tags.List(testUserID)
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	counts := make(map[string]int, len(tags))
+	for _, tag := range tags {
+		counts[tag.Name] = tag.DiaryCount
+	}
+	return counts
+}
+
+func TestSetDiaryTags(t *testing.T) {
+	store := newTestStore(t)
+	first := createDiary(t, store, "2026-01-01", 3, "A", "07:00", "23:00")
+	second := createDiary(t, store, "2026-01-02", 3, "A", "07:00", "23:00")
+
+	if err := store.Tags.SetDiaryTags(testUserID, first, []string{"work", "gym"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	if err := store.Tags.SetDiaryTags(testUserID, second, []string{"work"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	// 置き換えると外れたタグも残り、付いている日記の数だけが変わる
+	if err := store.Tags.SetDiaryTags(testUserID, first, []string{"reading", "work"}); err != nil {
+		t.Fatalf("replace tags: %v", err)
+	}
+
+	got, err := store.Tags.ListForDiaries([]string{first, second})
+	if err != nil {
+		t.Fatalf("list for diaries: %v", err)
+	}
+	want := map[string][]string{first: {"reading", "work"}, second: {"work"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+	if counts, want := tagCounts(t, store), map[string]int{"gym": 0, "reading": 1, "work": 2}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	store := newTestStore(t)
+	diaryID := createDiary(t, store, "2026-01-01", 3, "A", "07:00", "23:00")
+	if err := store.Tags.SetDiaryTags(testUserID, diaryID, []string{"work", "gym"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	ids := tagIDs(t, store)
+
+	if _, err := store.Tags.Rename(testUserID, ids["gym"], "work"); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("rename to an existing name: error = %v, want ErrDuplicate", err)
+	}
+	if renamed, err := store.Tags.Rename(testUserID, ids["gym"], "fitness"); err != nil || !renamed {
+		t.Errorf("rename: renamed = %v, error = %v", renamed, err)
+	}
+	if renamed, err := store.Tags.Rename("other-user", ids["work"], "job"); err != nil || renamed {
+		t.Errorf("rename another user's tag: renamed = %v, error = %v", renamed, err)
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	store := newTestStore(t)
+	both := createDiary(t, store, "2026-01-01", 3, "A", "07:00", "23:00")
+	sourceOnly := createDiary(t, store, "2026-01-02", 3, "A", "07:00", "23:00")
+	targetOnly := createDiary(t, store, "2026-01-03", 3, "A", "07:00", "23:00")
+	for diaryID, names := range map[string][]string{both: {"run", "running"}, sourceOnly: {"run"}, targetOnly: {"running"}} {
+		if err := store.Tags.SetDiaryTags(testUserID, diaryID, names); err != nil {
+			t.Fatalf("set tags: %v", err)
+		}
+	}
+	ids := tagIDs(t, store)
+
+	// 両方が付いた日記は重複せず、付与の一意制約に違反しない
+	if err := store.Tags.Merge(testUserID, ids["run"], ids["running"]); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	if counts, want := tagCounts(t, store), map[string]int{"running": 3}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+	if tag, err := store.Tags.GetByID(testUserID, ids["run"]); err != nil || tag != nil {
+		t.Errorf("source tag: %+v, error = %v", tag, err)
+	}
+	got, err := store.Tags.ListForDiaries([]string{both, sourceOnly, targetOnly})
+	if err != nil {
+		t.Fatalf("list for diaries: %v", err)
+	}
+	want := map[string][]string{both: {"running"}, sourceOnly: {"running"}, targetOnly: {"running"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+
+	// 別のユーザーのタグには付け替えない
+	if err := store.Tags.SetDiaryTags(testUserID, both, []string{"walk"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	ids = tagIDs(t, store)
+	if err := store.Tags.Merge("other-user", ids["walk"], ids["running"]); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if counts, want := tagCounts(t, store), map[string]int{"running": 2, "walk": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts after another user's merge = %v, want %v", counts, want)
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	store := newTestStore(t)
+	diaryID := createDiary(t, store, "2026-01-01", 3, "A", "07:00", "23:00")
+	if err := store.Tags.SetDiaryTags(testUserID, diaryID, []string{"work", "gym"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+
+	if deleted, err := store.Tags.Delete(testUserID, tagIDs(t, store)["work"]); err != nil || !deleted {
+		t.Fatalf("delete: deleted = %v, error = %v", deleted, err)
+	}
+	got, err := store.Tags.ListForDiaries([]string{diaryID})
+	if err != nil {
+		t.Fatalf("list for diaries: %v", err)
+	}
+	if want := map[string][]string{diaryID: {"gym"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+type sqlUserRepository struct {
+	db *sqlDB
+	d  dialect
+}
+
+func (r *sqlUserRepository) Create(user *model.User) error {
+	query := `
+		INSERT INTO users (id, username, email, password_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(query, user.ID, user.Username, user.Email, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	if err != nil && r.d.isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *sqlUserRepository) EnsureExists(user *model.User) error {
+	query := r.d.insertIgnore(`
+		INSERT INTO users (id, username, email, password_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	_, err := r.db.Exec(query, user.ID, user.Username, user.Email, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	return err
+}
+
+func (r *sqlUserRepository) GetByEmail(email string) (*model.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, created_at, updated_at
+		FROM users
+		WHERE email = ?
+	`
+
+	user := &model.User{}
+	err := r.db.QueryRow(query, email).Scan(
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
+		timeValue{&user.CreatedAt}, timeValue{&user.UpdatedAt},
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *sqlUserRepository) ExistsByEmail(email string) (bool, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users WHERE email = ?", email).Scan(&count)
+	return count > 0, err
+}
+
+func (r *sqlUserRepository) ExistsByUsername(username string) (bool, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users WHERE username = ?", username).Scan(&count)
+	return count > 0, err
+}
+
+func (r *sqlUserRepository) GetSettings(userID string) (*model.UserSettings, error) {
+	settings := &model.UserSettings{}
+	err := r.db.QueryRow("SELECT sleep_target_minutes, timezone FROM users WHERE id = ?", userID).
+		Scan(&settings.SleepTargetMinutes, &settings.Timezone)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (r *sqlUserRepository) UpdateSettings(userID string, settings *model.UserSettings) (bool, error) {
+	result, err := r.db.Exec("UPDATE users SET sleep_target_minutes = ?, timezone = ? WHERE id = ?",
+		settings.SleepTargetMinutes, settings.Timezone, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+
+	// dummyPasswordHash は登録されていないメールアドレスでのログインでも bcrypt の比較を行うためのハッシュ
+	// （応答時間からメールアドレスが登録済みかどうかを推測されないようにする。コストは登録時と同じ）
+	dummyPasswordHash = "$2a$10$2QyfTDB.VWwONxfzVqyOr.uaNs0N81IyCOOJzxG4BVznmUyusPBay"
+)
+
+var (
+	ErrEmailTaken         = conflict("DUPLICATE_ENTRY", "email already registered")
+	ErrUsernameTaken      = conflict("DUPLICATE_ENTRY", "username already taken")
+	ErrInvalidCredentials = unauthorized("UNAUTHORIZED", "Invalid email or password")
+	ErrInvalidToken       = unauthorized("INVALID_TOKEN", "Invalid or expired token")
+	ErrTokenReused        = unauthorized("INVALID_TOKEN", "Refresh token has already been used; the session has been revoked")
+	ErrSessionNotFound    = notFound("Session not found")
+)
+
+type tokenClaims struct {
+	TokenType string `json:"token_type"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// ClientInfo はセッション一覧に表示するクライアント情報
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+type AuthService struct {
+	users      repository.UserRepository
+	tokens     repository.RefreshTokenRepository
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewAuthService(users repository.UserRepository, tokens repository.RefreshTokenRepository, secret string, accessTTL, refreshTTL time.Duration) *AuthService {
+	return &AuthService{
+		users:      users,
+		tokens:     tokens,
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+func (s *AuthService) Register(req model.RegisterRequest, client ClientInfo) (*model.AuthResponse, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	exists, err := s.users.ExistsByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrEmailTaken
+	}
+	exists, err = s.users.ExistsByUsername(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrUsernameTaken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	user := &model.User{
+		ID:           uuid.New().String(),
+		Username:     req.Username,
+		Email:        email,
+		PasswordHash: string(hash),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := s.users.Create(user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			// 事前チェックと登録の間に同じメールアドレスで登録された
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+
+	token, err := s.issueTokens(user.ID, uuid.New().String(), client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthResponse{User: user, Token: *token}, nil
+}
+
+func (s *AuthService) Login(req model.LoginRequest, client ClientInfo) (*model.AuthResponse, error) {
+	user, err := s.users.GetByEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(req.Password))
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	token, err := s.issueTokens(user.ID, uuid.New().String(), client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthResponse{User: user, Token: *token}, nil
+}
+
+// Refresh はリフレッシュトークンをローテーションし、新しいトークンペアを発行する。
+// 既にローテーション済み・失効済みのトークンが再利用された場合は盗用とみなし、
+// 同じファミリー（セッション）のトークンをすべて失効させる。
+func (s *AuthService) Refresh(refreshToken string, client ClientInfo) (*model.TokenResponse, error) {
+	claims, err := s.parseToken(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	// 未使用・未失効の場合のみ使用済みにする（同時リクエストでも1回しか成功しない）
+	marked, err := s.tokens.MarkUsed(claims.Subject, claims.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !marked {
+		token, err := s.tokens.GetByID(claims.Subject, claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, ErrInvalidToken
+		}
+
+		if token.UsedAt != nil || token.RevokedAt != nil {
+			if err := s.tokens.RevokeFamily(claims.Subject, token.FamilyID, now); err != nil {
+				return nil, err
+			}
+			return nil, ErrTokenReused
+		}
+		return nil, ErrInvalidToken
+	}
+
+	return s.issueTokens(claims.Subject, claims.SessionID, client)
+}
+
+// ParseAccessToken はアクセストークンを検証し、ユーザーIDとセッションIDを返す。
+// ログアウト等で失効したセッションのトークンは無効とする。
+func (s *AuthService) ParseAccessToken(accessToken string) (string, string, error) {
+	claims, err := s.parseToken(accessToken, tokenTypeAccess)
+	if err != nil {
+		return "", "", err
+	}
+
+	active, err := s.tokens.FamilyActive(claims.Subject, claims.SessionID, time.Now().UTC())
+	if err != nil {
+		return "", "", err
+	}
+	if !active {
+		return "", "", ErrInvalidToken
+	}
+
+	return claims.Subject, claims.SessionID, nil
+}
+
+// Logout は現在のセッションを失効させる
+func (s *AuthService) Logout(userID, sessionID string) error {
+	return s.revokeFamily(userID, sessionID)
+}
+
+// GetSessions は有効なセッション（リフレッシュトークンのファミリー）の一覧を返す
+func (s *AuthService) GetSessions(userID, currentSessionID string) ([]model.Session, error) {
+	sessions, err := s.tokens.ListSessions(userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession は指定したセッションを失効させる
+func (s *AuthService) RevokeSession(userID, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+
+	active, err := s.tokens.FamilyActive(userID, sessionID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrSessionNotFound
+	}
+
+	return s.revokeFamily(userID, sessionID)
+}
+
+func (s *AuthService) revokeFamily(userID, familyID string) error {
+	return s.tokens.RevokeFamily(userID, familyID, time.Now().UTC())
+}
+
+func (s *AuthService) issueTokens(userID, sessionID string, client ClientInfo) (*model.TokenResponse, error) {
+	now := time.Now().UTC()
+	refreshID := uuid.New().String()
+
+	err := s.tokens.Create(&model.RefreshToken{
+		ID:        refreshID,
+		UserID:    userID,
+		FamilyID:  sessionID,
+		UserAgent: truncate(client.UserAgent, 255),
+		IPAddress: truncate(client.IPAddress, 45),
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.signToken(uuid.New().String(), userID, sessionID, tokenTypeAccess, now, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.signToken(refreshID, userID, sessionID, tokenTypeRefresh, now, s.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
+}
+
+func (s *AuthService) signToken(id, userID, sessionID, tokenType string, now time.Time, ttl time.Duration) (string, error) {
+	claims := tokenClaims{
+		TokenType: tokenType,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+func (s *AuthService) parseToken(tokenString, tokenType string) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.TokenType != tokenType || claims.Subject == "" || claims.SessionID == "" || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+// newTestAuthService はインメモリのバックエンドを使う AuthService を返す
+func newTestAuthService(t *testing.T, refreshTTL time.Duration) *AuthService {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	return NewAuthService(store.Users, store.RefreshTokens, "test-secret", 15*time.Minute, refreshTTL)
+}
+
+// register はテスト用のユーザーを登録し、そのトークンを返す
+func register(t *testing.T, s *AuthService) model.TokenResponse {
+	t.Helper()
+	resp, err := s.Register(model.RegisterRequest{Username: "tester", Email: "tester@example.com", Password: "password123"}, ClientInfo{UserAgent: "test"})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return resp.Token
+}
+
+func TestParseToken(t *testing.T) {
+	s := NewAuthService(nil, nil, "test-secret", 15*time.Minute, 24*time.Hour)
+	other := NewAuthService(nil, nil, "other-secret", 15*time.Minute, 24*time.Hour)
+	now := time.Now()
+
+	sign := func(s *AuthService, sessionID, tokenType string, ttl time.Duration) string {
+		t.Helper()
+		token, err := s.signToken("token-id", "user-id", sessionID, tokenType, now, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		tokenType string
+		wantErr   bool
+	}{
+		{name: "access token", token: sign(s, "session", tokenTypeAccess, time.Hour), tokenType: tokenTypeAccess},
+		{name: "refresh token", token: sign(s, "session", tokenTypeRefresh, time.Hour), tokenType: tokenTypeRefresh},
+		{name: "access token used as refresh token", token: sign(s, "session", tokenTypeAccess, time.Hour), tokenType: tokenTypeRefresh, wantErr: true},
+		{name: "refresh token used as access token", token: sign(s, "session", tokenTypeRefresh, time.Hour), tokenType: tokenTypeAccess, wantErr: true},
+		{name: "other secret", token: sign(other, "session", tokenTypeAccess, time.Hour), tokenType: tokenTypeAccess, wantErr: true},
+		{name: "expired", token: sign(s, "session", tokenTypeAccess, -time.Minute), tokenType: tokenTypeAccess, wantErr: true},
+		{name: "no session", token: sign(s, "", tokenTypeAccess, time.Hour), tokenType: tokenTypeAccess, wantErr: true},
+		{name: "garbage", token: "not-a-token", tokenType: tokenTypeAccess, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := s.parseToken(tt.token, tt.tokenType)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.Subject != "user-id" || claims.SessionID != "session" || claims.ID != "token-id" {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	s := newTestAuthService(t, 24*time.Hour)
+	first := register(t, s)
+
+	second, err := s.Refresh(first.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+	third, err := s.Refresh(second.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh the rotated token: %v", err)
+	}
+
+	// ローテーションしてもセッションは同じ
+	_, firstSession, err := s.ParseAccessToken(first.AccessToken)
+	if err != nil {
+		t.Fatalf("parse first access token: %v", err)
+	}
+	_, thirdSession, err := s.ParseAccessToken(third.AccessToken)
+	if err != nil {
+		t.Fatalf("parse third access token: %v", err)
+	}
+	if firstSession != thirdSession {
+		t.Errorf("session changed from %s to %s", firstSession, thirdSession)
+	}
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	s := newTestAuthService(t, 24*time.Hour)
+	first := register(t, s)
+	second, err := s.Refresh(first.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	// 使用済みのトークンの再利用は盗用とみなす
+	if _, err := s.Refresh(first.RefreshToken, ClientInfo{}); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("reuse: error = %v, want ErrTokenReused", err)
+	}
+
+	// 同じセッションの最新のトークンも使えなくなる
+	if _, err := s.Refresh(second.RefreshToken, ClientInfo{}); err == nil {
+		t.Error("the latest refresh token still works after reuse")
+	}
+	if _, _, err := s.ParseAccessToken(second.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("access token: error = %v, want ErrInvalidToken", err)
+	}
+}
+
+// Refresh は署名と種類を検証してからトークンを参照するため、不正なトークンは DB を使わずに拒否される
+func TestRefreshInvalidTokens(t *testing.T) {
+	s := NewAuthService(nil, nil, "test-secret", 15*time.Minute, 24*time.Hour)
+	access, err := s.signToken("id", "user", "session", tokenTypeAccess, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewAuthService(nil, nil, "other-secret", 15*time.Minute, 24*time.Hour)
+	forged, err := other.signToken("id", "user", "session", tokenTypeRefresh, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "garbage", token: "not-a-token"},
+		{name: "access token", token: access},
+		{name: "other secret", token: forged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Refresh(tt.token, ClientInfo{}); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	s := newTestAuthService(t, 24*time.Hour)
+	token := register(t, s)
+	userID, sessionID, err := s.ParseAccessToken(token.AccessToken)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	login, err := s.Login(model.LoginRequest{Email: "TESTER@example.com", Password: "password123"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	sessions, err := s.GetSessions(userID, sessionID)
+	if err != nil {
+		t.Fatalf("sessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %d, want 2", len(sessions))
+	}
+
+	if err := s.Logout(userID, sessionID); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if _, _, err := s.ParseAccessToken(token.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("access token after logout: error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := s.Refresh(token.RefreshToken, ClientInfo{}); err == nil {
+		t.Error("refresh token still works after logout")
+	}
+	// 別のセッションはそのまま使える
+	if _, err := s.Refresh(login.Token.RefreshToken, ClientInfo{}); err != nil {
+		t.Errorf("other session: %v", err)
+	}
+	if err := s.RevokeSession(userID, sessionID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("revoke a logged-out session: error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestExpiredRefreshTokenEndsSession(t *testing.T) {
+	s := newTestAuthService(t, time.Millisecond)
+	token := register(t, s)
+	time.Sleep(10 * time.Millisecond)
+
+	// アクセストークン自体は有効期限内でも、リフレッシュトークンが切れたセッションは無効
+	if _, _, err := s.ParseAccessToken(token.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestLoginInvalidCredentials(t *testing.T) {
+	s := newTestAuthService(t, 24*time.Hour)
+	register(t, s)
+
+	tests := []struct {
+		name string
+		req  model.LoginRequest
+	}{
+		{name: "wrong password", req: model.LoginRequest{Email: "tester@example.com", Password: "wrong-password"}},
+		{name: "unknown email", req: model.LoginRequest{Email: "nobody@example.com", Password: "password123"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Login(tt.req, ClientInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("error = %v, want ErrInvalidCredentials", err)
+			}
+		})
+	}
+}
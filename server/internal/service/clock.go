@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+// parseClock は HH:MM を 0:00 からの経過分に変換する
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func formatClock(minutes int) string {
+	minutes = ((minutes % minutesPerDay) + minutesPerDay) % minutesPerDay
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// clockStats は HH:MM の時刻の円周平均と円周標準偏差（分）を返す。
+// 時刻を 24 時間周期の角度として扱うため、23:30 と 00:30 の平均は 00:00 になる。
+// 時刻が全くばらばら（平均ベクトルが 0）で平均が定まらない場合は ok=false を返す。
+func clockStats(times []string) (mean string, stdDev float64, ok bool) {
+	var sumSin, sumCos float64
+	n := 0
+	for _, s := range times {
+		m, valid := parseClock(s)
+		if !valid {
+			continue
+		}
+		angle := float64(m) / minutesPerDay * 2 * math.Pi
+		sumSin += math.Sin(angle)
+		sumCos += math.Cos(angle)
+		n++
+	}
+	if n == 0 {
+		return "", 0, false
+	}
+
+	// 平均合成ベクトル長 R（1 に近いほど時刻がそろっている）
+	r := math.Hypot(sumSin, sumCos) / float64(n)
+	if r < 1e-9 {
+		return "", 0, false
+	}
+	r = math.Min(r, 1)
+
+	angle := math.Atan2(sumSin, sumCos)
+	meanMinutes := int(math.Round(angle / (2 * math.Pi) * minutesPerDay))
+
+	// 円周標準偏差 sqrt(-2 ln R) をラジアンから分に換算する。
+	// R = 1 では -2 ln R が -0 になり、そのままでは -0 を返すため 0 以上にそろえる。
+	sd := math.Sqrt(math.Max(0, -2*math.Log(r))) / (2 * math.Pi) * minutesPerDay
+
+	return formatClock(meanMinutes), math.Round(sd*10) / 10, true
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClockStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		times      []string
+		wantMean   string
+		wantStdDev float64
+		wantOK     bool
+	}{
+		{name: "same times", times: []string{"07:00", "07:00", "07:00"}, wantMean: "07:00", wantStdDev: 0, wantOK: true},
+		{name: "single time", times: []string{"23:15"}, wantMean: "23:15", wantStdDev: 0, wantOK: true},
+		{name: "spread in the morning", times: []string{"07:00", "07:30", "08:00"}, wantMean: "07:30", wantStdDev: 24.5, wantOK: true},
+		{name: "across midnight", times: []string{"23:30", "00:30"}, wantMean: "00:00", wantStdDev: 30, wantOK: true},
+		{name: "invalid times are skipped", times: []string{"23:30", "", "25:00", "00:30"}, wantMean: "00:00", wantStdDev: 30, wantOK: true},
+		{name: "opposite times have no mean", times: []string{"06:00", "18:00"}, wantOK: false},
+		{name: "no valid times", times: []string{"", "xx"}, wantOK: false},
+		{name: "empty", times: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, stdDev, ok := clockStats(tt.times)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if mean != tt.wantMean {
+				t.Errorf("mean = %q, want %q", mean, tt.wantMean)
+			}
+			if stdDev != tt.wantStdDev || math.Signbit(stdDev) {
+				t.Errorf("stdDev = %v, want %v", stdDev, tt.wantStdDev)
+			}
+		})
+	}
+}
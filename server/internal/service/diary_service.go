@@ -1,31 +1,78 @@
 package service
 
 import (
-	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
-	"github.com/nana743533/260219-diary-app/server/internal/model"
 	"github.com/google/uuid"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
 )
 
+var errDiaryNotFound = notFound("Diary not found")
+
 type DiaryService struct {
-	db *sql.DB
+	repo    repository.DiaryRepository
+	users   repository.UserRepository
+	freezes repository.StreakFreezeRepository
+	tags    repository.TagRepository
+	metrics repository.MetricRepository
+	search  repository.SearchIndexRepository
+	tx      repository.Transactor
 }
 
-func NewDiaryService(db *sql.DB) *DiaryService {
-	return &DiaryService{db: db}
+func NewDiaryService(repo repository.DiaryRepository, users repository.UserRepository, freezes repository.StreakFreezeRepository, tags repository.TagRepository, metrics repository.MetricRepository, search repository.SearchIndexRepository, tx repository.Transactor) *DiaryService {
+	return &DiaryService{repo: repo, users: users, freezes: freezes, tags: tags, metrics: metrics, search: search, tx: tx}
 }
 
-func (s *DiaryService) Create(userID string, req model.CreateDiaryRequest) (*model.Diary, error) {
-	id := uuid.New().String()
+// Create は tz（X-Timezone）またはユーザー設定のタイムゾーンで未来の日付の日記を作成できない
+func (s *DiaryService) Create(userID, tz string, req model.CreateDiaryRequest) (*model.Diary, error) {
+	loc, err := s.location(userID, tz)
+	if err != nil {
+		return nil, err
+	}
+	if req.Date > today(loc).Format("2006-01-02") {
+		return nil, fieldValidation("date", "未来の日付は指定できません")
+	}
+
+	values, err := s.metricValues(userID, req.Metrics)
+	if err != nil {
+		return nil, err
+	}
 	now := time.Now()
 
-	query := `
-		INSERT INTO diaries (id, user_id, date, rating, progress, wake_up_time, sleep_time, memo, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
-	_, err := s.db.Exec(query, id, userID, req.Date, req.Rating, req.Progress, req.WakeUpTime, req.SleepTime, req.Memo, now, now)
+	diary := &model.Diary{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Date:       req.Date,
+		Rating:     req.Rating,
+		Progress:   req.Progress,
+		WakeUpTime: req.WakeUpTime,
+		SleepTime:  req.SleepTime,
+		Memo:       req.Memo,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	// 日記・タグ・記録項目の値・索引は、途中で失敗した場合にすべて取り消す
+	err = s.tx.WithTx(func(r repository.Repos) error {
+		if err := r.Diaries.Create(diary); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return conflict("DIARY_ALREADY_EXISTS", "Diary for this date already exists")
+			}
+			return err
+		}
+		if err := r.Tags.SetDiaryTags(userID, diary.ID, normalizeTags(req.Tags)); err != nil {
+			return err
+		}
+		if err := r.Metrics.SetDiaryValues(diary.ID, values); err != nil {
+			return err
+		}
+		return indexMemo(r.SearchIndex, userID, diary.ID, diary.Memo)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -33,69 +80,152 @@ func (s *DiaryService) Create(userID string, req model.CreateDiaryRequest) (*mod
 	return s.GetByDate(userID, req.Date)
 }
 
+// GetByDate は該当する日記がない場合 ErrNotFound を返す
 func (s *DiaryService) GetByDate(userID, date string) (*model.Diary, error) {
-	query := `
-		SELECT id, user_id, date, rating, progress, wake_up_time, sleep_time, memo, created_at, updated_at
-		FROM diaries
-		WHERE user_id = ? AND date = ?
-	`
-
-	diary := &model.Diary{}
-	err := s.db.QueryRow(query, userID, date).Scan(
-		&diary.ID, &diary.UserID, &diary.Date, &diary.Rating, &diary.Progress,
-		&diary.WakeUpTime, &diary.SleepTime, &diary.Memo, &diary.CreatedAt, &diary.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
+	diary, err := s.repo.GetByDate(userID, date)
+	if err != nil {
+		return nil, err
+	}
+	if diary == nil {
+		return nil, errDiaryNotFound
 	}
+
+	prev, err := s.repo.GetByDate(userID, addDays(date, -1))
 	if err != nil {
 		return nil, err
 	}
+	if prev != nil {
+		if d, ok := sleepDuration(prev.SleepTime, diary.WakeUpTime); ok {
+			diary.SleepDurationMinutes = &d
+		}
+	}
 
-	return diary, nil
+	diaries := []model.Diary{*diary}
+	if err := s.withTags(diaries); err != nil {
+		return nil, err
+	}
+	if err := s.withMetrics(userID, diaries); err != nil {
+		return nil, err
+	}
+	return &diaries[0], nil
 }
 
-func (s *DiaryService) GetAll(userID, startDate, endDate string, limit, offset int) ([]model.Diary, error) {
-	query := `
-		SELECT id, user_id, date, rating, progress, wake_up_time, sleep_time, memo, created_at, updated_at
-		FROM diaries
-		WHERE user_id = ?
-	`
-	args := []interface{}{userID}
+const (
+	// defaultDiaryLimit は日記一覧の limit を省略した場合の件数
+	defaultDiaryLimit = 30
+	// maxDiaryLimit は日記一覧の limit の上限
+	maxDiaryLimit = 100
+)
 
-	if startDate != "" {
-		query += " AND date >= ?"
-		args = append(args, startDate)
+// GetAll は日記を q.Sort / q.Order の順（既定は新しい順）に返す。q.Filter は絞り込み式（parseDiaryFilter）。
+// q.Cursor を指定した場合は日付をキーにしたカーソル方式になり、途中で日記が追加・削除されても重複や抜けが起きない。
+func (s *DiaryService) GetAll(userID string, q model.ListDiariesQuery) (*model.DiaryList, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultDiaryLimit
 	}
-	if endDate != "" {
-		query += " AND date <= ?"
-		args = append(args, endDate)
+	if limit > maxDiaryLimit {
+		limit = maxDiaryLimit
 	}
 
-	query += " ORDER BY date DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
+	if q.StartDate != "" && q.EndDate != "" && q.StartDate > q.EndDate {
+		return nil, validation("start_date must be on or before end_date")
+	}
 
-	rows, err := s.db.Query(query, args...)
+	order := repository.DiaryOrder{Field: q.Sort, Ascending: q.Order == "asc"}
+	expr, err := parseDiaryFilter(q.Filter)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var diaries []model.Diary
-	for rows.Next() {
-		var d model.Diary
-		err := rows.Scan(
-			&d.ID, &d.UserID, &d.Date, &d.Rating, &d.Progress,
-			&d.WakeUpTime, &d.SleepTime, &d.Memo, &d.CreatedAt, &d.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+	// カーソル方式では前のページの最後の日付より前だけを対象にする
+	endDate := q.EndDate
+	if q.Cursor != nil {
+		if q.Offset != 0 {
+			return nil, validation("offset cannot be combined with cursor")
+		}
+		if (order.Field != "" && order.Field != "date") || order.Ascending {
+			return nil, validation("cursor can only be used with sort=date and order=desc")
 		}
-		diaries = append(diaries, d)
+		if *q.Cursor != "" {
+			before, ok := decodeCursor(*q.Cursor)
+			if !ok {
+				return nil, validation("Invalid cursor")
+			}
+			if last := addDays(before, -1); endDate == "" || last < endDate {
+				endDate = last
+			}
+		}
+	}
+
+	tags, matchAny, err := tagFilter(q.Tags, q.TagMatch)
+	if err != nil {
+		return nil, err
+	}
+	filter := repository.DiaryFilter{StartDate: q.StartDate, EndDate: q.EndDate, Tags: tags, MatchAnyTag: matchAny, Expr: expr}
+
+	total, err := s.repo.Count(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 次のページがあるかを判定するため1件多く取得する
+	filter.EndDate = endDate
+	diaries, err := s.repo.GetAll(userID, filter, order, limit+1, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(diaries) > limit
+	if hasMore {
+		diaries = diaries[:limit]
+	}
+	if diaries == nil {
+		diaries = []model.Diary{}
+	}
+	if err := s.withSleepDurations(userID, diaries); err != nil {
+		return nil, err
+	}
+	if err := s.withTags(diaries); err != nil {
+		return nil, err
+	}
+	if err := s.withMetrics(userID, diaries); err != nil {
+		return nil, err
 	}
 
-	return diaries, nil
+	list := &model.DiaryList{
+		Diaries: diaries,
+		Pagination: model.Pagination{
+			Total:   total,
+			Limit:   limit,
+			Offset:  q.Offset,
+			HasMore: hasMore,
+		},
+	}
+	if hasMore && q.Cursor != nil {
+		cursor := encodeCursor(diaries[len(diaries)-1].Date)
+		list.Pagination.NextCursor = &cursor
+	}
+	return list, nil
+}
+
+// encodeCursor は日付からカーソル文字列を作る。クライアントは中身に依存しないこと。
+func encodeCursor(date string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("date:" + date))
+}
+
+func decodeCursor(cursor string) (string, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", false
+	}
+	date, ok := strings.CutPrefix(string(b), "date:")
+	if !ok {
+		return "", false
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", false
+	}
+	return date, true
 }
 
 func (s *DiaryService) Update(userID, date string, req model.UpdateDiaryRequest) (*model.Diary, error) {
@@ -103,46 +233,60 @@ func (s *DiaryService) Update(userID, date string, req model.UpdateDiaryRequest)
 	if err != nil {
 		return nil, err
 	}
-	if existing == nil {
-		return nil, sql.ErrNoRows
+	values, err := s.metricValues(userID, req.Metrics)
+	if err != nil {
+		return nil, err
 	}
 
-	updates := []string{}
-	args := []interface{}{}
-
+	changed := false
 	if req.Rating != nil {
-		updates = append(updates, "rating = ?")
-		args = append(args, *req.Rating)
+		existing.Rating = *req.Rating
+		changed = true
 	}
 	if req.Progress != nil {
-		updates = append(updates, "progress = ?")
-		args = append(args, *req.Progress)
+		existing.Progress = *req.Progress
+		changed = true
 	}
 	if req.WakeUpTime != nil {
-		updates = append(updates, "wake_up_time = ?")
-		args = append(args, *req.WakeUpTime)
+		existing.WakeUpTime = *req.WakeUpTime
+		changed = true
 	}
 	if req.SleepTime != nil {
-		updates = append(updates, "sleep_time = ?")
-		args = append(args, *req.SleepTime)
+		existing.SleepTime = *req.SleepTime
+		changed = true
 	}
 	if req.Memo != nil {
-		updates = append(updates, "memo = ?")
-		args = append(args, *req.Memo)
+		existing.Memo = *req.Memo
+		changed = true
 	}
 
-	if len(updates) == 0 {
+	if req.Tags != nil || len(values) > 0 {
+		changed = true
+	}
+	if !changed {
 		return existing, nil
 	}
 
-	updates = append(updates, "updated_at = ?")
-	args = append(args, time.Now())
-	args = append(args, userID, date)
-
-	query := fmt.Sprintf("UPDATE diaries SET %s WHERE user_id = ? AND date = ?",
-		joinStrings(updates, ", "))
-
-	_, err = s.db.Exec(query, args...)
+	existing.UpdatedAt = time.Now()
+	err = s.tx.WithTx(func(r repository.Repos) error {
+		if req.Tags != nil {
+			if err := r.Tags.SetDiaryTags(userID, existing.ID, normalizeTags(*req.Tags)); err != nil {
+				return err
+			}
+		}
+		if len(values) > 0 {
+			if err := r.Metrics.SetDiaryValues(existing.ID, values); err != nil {
+				return err
+			}
+		}
+		if err := r.Diaries.Update(existing); err != nil {
+			return err
+		}
+		if req.Memo != nil {
+			return indexMemo(r.SearchIndex, userID, existing.ID, existing.Memo)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -150,38 +294,34 @@ func (s *DiaryService) Update(userID, date string, req model.UpdateDiaryRequest)
 	return s.GetByDate(userID, date)
 }
 
+// Delete は該当する日記がない場合 ErrNotFound を返す
 func (s *DiaryService) Delete(userID, date string) error {
-	query := "DELETE FROM diaries WHERE user_id = ? AND date = ?"
-	_, err := s.db.Exec(query, userID, date)
-	return err
+	deleted, err := s.repo.Delete(userID, date)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errDiaryNotFound
+	}
+	return nil
 }
 
 func (s *DiaryService) GetCalendarData(userID string, year, month int) (*model.CalendarResponse, error) {
-	startDate := fmt.Sprintf("%04d-%02d-01", year, month)
-	endDate := fmt.Sprintf("%04d-%02d-31", year, month)
-
-	query := `
-		SELECT date, rating
-		FROM diaries
-		WHERE user_id = ? AND date >= ? AND date <= ?
-		ORDER BY date
-	`
+	// 月の日数を計算
+	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1)
+	totalDays := lastDay.Day()
 
-	rows, err := s.db.Query(query, userID, startDate, endDate)
+	entries, err := s.repo.GetCalendarEntries(userID, repository.DiaryFilter{
+		StartDate: firstDay.Format("2006-01-02"),
+		EndDate:   lastDay.Format("2006-01-02"),
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var entries []model.CalendarEntry
 	var totalRating int
-	for rows.Next() {
-		var e model.CalendarEntry
-		err := rows.Scan(&e.Date, &e.Rating)
-		if err != nil {
-			return nil, err
-		}
-		entries = append(entries, e)
+	for _, e := range entries {
 		totalRating += e.Rating
 	}
 
@@ -190,11 +330,6 @@ func (s *DiaryService) GetCalendarData(userID string, year, month int) (*model.C
 		avgRating = float64(totalRating) / float64(len(entries))
 	}
 
-	// 月の日数を計算
-	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	lastDay := firstDay.AddDate(0, 1, -1)
-	totalDays := lastDay.Day()
-
 	return &model.CalendarResponse{
 		Year:    year,
 		Month:   month,
@@ -207,158 +342,239 @@ func (s *DiaryService) GetCalendarData(userID string, year, month int) (*model.C
 	}, nil
 }
 
-func (s *DiaryService) GetStatistics(userID, period string) (*model.Statistics, error) {
-	// 期間の計算
-	var startDate, endDate string
-	now := time.Now()
+// maxCalendarRangeDays は期間指定のカレンダーで取得できる最大の日数
+const maxCalendarRangeDays = 366
 
-	switch period {
-	case "week":
-		startDate = now.AddDate(0, 0, -7).Format("2006-01-02")
-		endDate = now.Format("2006-01-02")
-	case "year":
-		startDate = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
-		endDate = time.Date(now.Year(), 12, 31, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
-	default: // month
-		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
-		endDate = time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1).Format("2006-01-02")
-		period = "month"
+// GetCalendarRange は startDate〜endDate の日付・評価・進捗・メモの有無を返す。
+// tags（カンマ区切り）を指定した場合はそのタグが付いた日記に絞る（tagMatch は GetAll と同じ）。
+func (s *DiaryService) GetCalendarRange(userID, startDate, endDate, tags, tagMatch string) ([]model.CalendarEntry, error) {
+	if startDate == "" || endDate == "" {
+		return nil, validation("start_date and end_date are required")
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, validation("start_date must be YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, validation("end_date must be YYYY-MM-DD")
+	}
+	if start.After(end) {
+		return nil, validation("start_date must be on or before end_date")
+	}
+	if end.After(start.AddDate(0, 0, maxCalendarRangeDays-1)) {
+		return nil, validation(fmt.Sprintf("The range must be %d days or less", maxCalendarRangeDays))
 	}
 
-	// 基本統計
-	query := `
-		SELECT
-			COUNT(*) as total,
-			AVG(rating) as avg_rating,
-			SUM(CASE WHEN rating = 1 THEN 1 ELSE 0 END) as r1,
-			SUM(CASE WHEN rating = 2 THEN 1 ELSE 0 END) as r2,
-			SUM(CASE WHEN rating = 3 THEN 1 ELSE 0 END) as r3,
-			SUM(CASE WHEN rating = 4 THEN 1 ELSE 0 END) as r4,
-			SUM(CASE WHEN rating = 5 THEN 1 ELSE 0 END) as r5,
-			SUM(CASE WHEN progress = 'A' THEN 1 ELSE 0 END) as pa,
-			SUM(CASE WHEN progress = 'B' THEN 1 ELSE 0 END) as pb,
-			SUM(CASE WHEN progress = 'C' THEN 1 ELSE 0 END) as pc
-		FROM diaries
-		WHERE user_id = ? AND date >= ? AND date <= ?
-	`
-
-	stats := &model.Statistics{
-		Period:               period,
-		PeriodStart:          startDate,
-		PeriodEnd:            endDate,
-		RatingDistribution:   make(map[string]int),
-		ProgressDistribution: make(map[string]int),
-	}
-
-	var avgRating sql.NullFloat64
-	var r1, r2, r3, r4, r5, pa, pb, pc int
-
-	err := s.db.QueryRow(query, userID, startDate, endDate).Scan(
-		&stats.TotalEntries, &avgRating,
-		&r1, &r2, &r3, &r4, &r5,
-		&pa, &pb, &pc,
-	)
+	names, matchAny, err := tagFilter(tags, tagMatch)
 	if err != nil {
 		return nil, err
 	}
 
-	if avgRating.Valid {
-		stats.AverageRating = avgRating.Float64
+	entries, err := s.repo.GetCalendarEntries(userID, repository.DiaryFilter{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Tags:        names,
+		MatchAnyTag: matchAny,
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	stats.RatingDistribution = map[string]int{"1": r1, "2": r2, "3": r3, "4": r4, "5": r5}
-	stats.ProgressDistribution = map[string]int{"A": pa, "B": pb, "C": pc}
-
-	// 連続記録日数の計算
-	stats.LongestStreak = s.calculateLongestStreak(userID)
-
-	return stats, nil
+	if entries == nil {
+		entries = []model.CalendarEntry{}
+	}
+	return entries, nil
 }
 
-func (s *DiaryService) GetTrend(userID string, days int) (*model.TrendData, error) {
-	startDate := time.Now().AddDate(0, 0, -days).Format("2006-01-02")
-
-	query := `
-		SELECT date, rating
-		FROM diaries
-		WHERE user_id = ? AND date >= ?
-		ORDER BY date
-	`
+// GetStatistics は period（week / month / year / all）または startDate〜endDate の期間で集計する。
+// startDate / endDate を指定した場合、period は "custom" になる。
+// compare に "previous" を指定した場合は直前の同等の期間と比較した結果も返す。
+func (s *DiaryService) GetStatistics(userID, tz, period, startDate, endDate, compare string) (*model.Statistics, error) {
+	if compare != "" && compare != "previous" {
+		return nil, validation("compare must be previous")
+	}
+	startDate, endDate, period, err := s.statisticsPeriod(userID, tz, period, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if compare != "" && period == "all" {
+		return nil, validation("compare cannot be used with period=all")
+	}
 
-	rows, err := s.db.Query(query, userID, startDate)
+	stats, err := s.summarize(userID, period, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var data []model.TrendEntry
-	for rows.Next() {
-		var e model.TrendEntry
-		err := rows.Scan(&e.Date, &e.Rating)
+	if compare != "" {
+		prevStart, prevEnd := previousPeriod(period, startDate, endDate)
+		previous, err := s.summarize(userID, period, prevStart, prevEnd)
 		if err != nil {
 			return nil, err
 		}
-		data = append(data, e)
+		stats.Comparison = compareStatistics(stats, previous)
 	}
 
-	return &model.TrendData{
-		PeriodDays: days,
-		Data:       data,
-	}, nil
+	return stats, nil
 }
 
-func (s *DiaryService) calculateLongestStreak(userID string) int {
-	query := `
-		SELECT date FROM diaries
-		WHERE user_id = ?
-		ORDER BY date
-	`
+// summarize は startDate〜endDate の統計を集計する
+func (s *DiaryService) summarize(userID, period, startDate, endDate string) (*model.Statistics, error) {
+	// 基本統計
+	stats, err := s.repo.GetStatistics(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Period = period
+	stats.PeriodStart = startDate
+	stats.PeriodEnd = endDate
+
+	diaries, err := s.repo.GetRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 平均起床・就寝時刻
+	fillClockStats(stats, diaries)
+
+	// 期間内の連続記録日数（フリーズ日で補った連続を含む）
+	dates := make([]string, len(diaries))
+	for i, d := range diaries {
+		dates[i] = d.Date
+	}
+	freezes, err := s.freezes.List(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	stats.LongestStreak = longestStreak(buildStreaks(dates, freezes))
 
-	rows, err := s.db.Query(query, userID)
+	// タグごとの記録数と平均評価
+	stats.TagStats, err = s.tagStats(userID, startDate, endDate)
 	if err != nil {
-		return 0
+		return nil, err
 	}
-	defer rows.Close()
 
-	var dates []string
-	for rows.Next() {
-		var d string
-		rows.Scan(&d)
-		dates = append(dates, d)
+	// 記録項目ごとの集計
+	stats.MetricStats, err = s.metricStats(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(dates) == 0 {
-		return 0
+	return stats, nil
+}
+
+// previousPeriod は比較対象となる直前の期間を返す。
+// month / year は前月・前年の全体、それ以外は同じ日数だけ前にずらした期間。
+func previousPeriod(period, startDate, endDate string) (string, string) {
+	start, _ := time.Parse("2006-01-02", startDate)
+	end, _ := time.Parse("2006-01-02", endDate)
+
+	switch period {
+	case "month":
+		return start.AddDate(0, -1, 0).Format("2006-01-02"), start.AddDate(0, 0, -1).Format("2006-01-02")
+	case "year":
+		return start.AddDate(-1, 0, 0).Format("2006-01-02"), start.AddDate(0, 0, -1).Format("2006-01-02")
+	default:
+		days := int(end.Sub(start).Hours()/24) + 1
+		return start.AddDate(0, 0, -days).Format("2006-01-02"), start.AddDate(0, 0, -1).Format("2006-01-02")
 	}
+}
 
-	maxStreak := 1
-	currentStreak := 1
+// compareStatistics は current と previous の差分（current - previous）を求める
+func compareStatistics(current, previous *model.Statistics) *model.StatisticsComparison {
+	delta := model.StatisticsDelta{
+		TotalEntries:         current.TotalEntries - previous.TotalEntries,
+		LongestStreak:        current.LongestStreak - previous.LongestStreak,
+		ProgressDistribution: map[string]int{},
+	}
+	if current.TotalEntries > 0 && previous.TotalEntries > 0 {
+		d := math.Round((current.AverageRating-previous.AverageRating)*100) / 100
+		delta.AverageRating = &d
+	}
+	for k, v := range current.ProgressDistribution {
+		delta.ProgressDistribution[k] += v
+	}
+	for k, v := range previous.ProgressDistribution {
+		delta.ProgressDistribution[k] -= v
+	}
 
-	for i := 1; i < len(dates); i++ {
-		prev, _ := time.Parse("2006-01-02", dates[i-1])
-		curr, _ := time.Parse("2006-01-02", dates[i])
+	return &model.StatisticsComparison{
+		Previous: previous,
+		Delta:    delta,
+	}
+}
 
-		if curr.Sub(prev).Hours() == 24 {
-			currentStreak++
-		} else {
-			currentStreak = 1
+// statisticsPeriod は集計期間の開始日・終了日と period の表示名を返す。
+// 今日の日付は tz（空の場合はユーザー設定）のタイムゾーンで決める。
+func (s *DiaryService) statisticsPeriod(userID, tz, period, startDate, endDate string) (string, string, string, error) {
+	if startDate != "" || endDate != "" {
+		if period != "" && period != "custom" {
+			return "", "", "", validation("period cannot be combined with start_date and end_date")
+		}
+		if startDate == "" || endDate == "" {
+			return "", "", "", validation("start_date and end_date must be specified together")
 		}
+		start, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return "", "", "", validation("start_date must be YYYY-MM-DD")
+		}
+		end, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return "", "", "", validation("end_date must be YYYY-MM-DD")
+		}
+		if start.After(end) {
+			return "", "", "", validation("start_date must be on or before end_date")
+		}
+		return startDate, endDate, "custom", nil
+	}
+
+	loc, err := s.location(userID, tz)
+	if err != nil {
+		return "", "", "", err
+	}
+	now := today(loc)
 
-		if currentStreak > maxStreak {
-			maxStreak = currentStreak
+	switch period {
+	case "week":
+		startDate = now.AddDate(0, 0, -7).Format("2006-01-02")
+		endDate = now.Format("2006-01-02")
+	case "year":
+		startDate = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+		endDate = time.Date(now.Year(), 12, 31, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	case "month", "":
+		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+		endDate = time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1).Format("2006-01-02")
+		period = "month"
+	case "all":
+		// 最初の記録日から今日まで（記録がない場合は今日のみ）
+		endDate = now.Format("2006-01-02")
+		startDate = endDate
+		dates, err := s.repo.GetDates(userID)
+		if err != nil {
+			return "", "", "", err
+		}
+		if len(dates) > 0 && dates[0] < startDate {
+			startDate = dates[0]
+		}
+		if len(dates) > 0 && dates[len(dates)-1] > endDate {
+			endDate = dates[len(dates)-1]
 		}
+	default:
+		return "", "", "", validation("period must be one of week, month, year, all")
 	}
 
-	return maxStreak
+	return startDate, endDate, period, nil
 }
 
-func joinStrings(strs []string, sep string) string {
-	if len(strs) == 0 {
-		return ""
+func fillClockStats(stats *model.Statistics, diaries []model.Diary) {
+	wakeUps := make([]string, len(diaries))
+	sleeps := make([]string, len(diaries))
+	for i, d := range diaries {
+		wakeUps[i] = d.WakeUpTime
+		sleeps[i] = d.SleepTime
 	}
-	result := strs[0]
-	for i := 1; i < len(strs); i++ {
-		result += sep + strs[i]
-	}
-	return result
+
+	stats.AverageWakeUpTime, stats.WakeUpTimeStdDev, _ = clockStats(wakeUps)
+	stats.AverageSleepTime, stats.SleepTimeStdDev, _ = clockStats(sleeps)
 }
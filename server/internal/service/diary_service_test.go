@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+const testUserID = "test-user"
+
+// newTestDiaryService はインメモリのバックエンドを使う DiaryService を返す
+func newTestDiaryService(t *testing.T) *DiaryService {
+	t.Helper()
+	return diaryServiceFor(repository.NewMemoryStore())
+}
+
+// diaryServiceFor は store を使う DiaryService を返す（タグや記録項目のサービスと store を共有する場合に使う）
+func diaryServiceFor(store *repository.Store) *DiaryService {
+	return NewDiaryService(store.Diaries, store.Users, store.StreakFreezes, store.Tags, store.Metrics, store.SearchIndex, store)
+}
+
+// createDiaries は reqs の日記を作成する
+func createDiaries(t *testing.T, s *DiaryService, reqs ...model.CreateDiaryRequest) {
+	t.Helper()
+	for _, req := range reqs {
+		if _, err := s.Create(testUserID, "UTC", req); err != nil {
+			t.Fatalf("create %s: %v", req.Date, err)
+		}
+	}
+}
+
+func TestGetAllFilter(t *testing.T) {
+	s := newTestDiaryService(t)
+	createDiaries(t, s,
+		model.CreateDiaryRequest{Date: "2026-01-01", Rating: 5, Progress: "A", WakeUpTime: "06:30", SleepTime: "22:30", Tags: []string{"work"}},
+		model.CreateDiaryRequest{Date: "2026-01-02", Rating: 4, Progress: "B", WakeUpTime: "07:30", SleepTime: "23:30", Tags: []string{"work", "gym"}},
+		model.CreateDiaryRequest{Date: "2026-01-03", Rating: 2, Progress: "C", WakeUpTime: "09:00", SleepTime: "01:30"},
+		model.CreateDiaryRequest{Date: "2026-01-04", Rating: 4, Progress: "A", WakeUpTime: "08:00", SleepTime: "00:30", Tags: []string{"gym"}},
+	)
+
+	tests := []struct {
+		name   string
+		filter string
+		sort   string
+		order  string
+		want   []string
+	}{
+		{name: "no filter", want: []string{"2026-01-04", "2026-01-03", "2026-01-02", "2026-01-01"}},
+		{name: "rating and progress", filter: "rating>=4 AND progress:A", want: []string{"2026-01-04", "2026-01-01"}},
+		{name: "tag", filter: "tag=gym", want: []string{"2026-01-04", "2026-01-02"}},
+		{name: "without tag", filter: "NOT tag=work", want: []string{"2026-01-04", "2026-01-03"}},
+		{name: "tag not equal", filter: "tag!=work", want: []string{"2026-01-04", "2026-01-03"}},
+		{name: "sleep time before midnight", filter: "sleep_time<23:00", want: []string{"2026-01-01"}},
+		{name: "sleep time after midnight", filter: "sleep_time>23:00", want: []string{"2026-01-04", "2026-01-03", "2026-01-02"}},
+		{name: "sleep time range across midnight", filter: "sleep_time>=23:00 AND sleep_time<=01:00", want: []string{"2026-01-04", "2026-01-02"}},
+		{name: "or with parentheses", filter: "(rating=2 OR tag=work) AND wake_up_time<09:00", want: []string{"2026-01-02", "2026-01-01"}},
+		{name: "sort by rating", sort: "rating", order: "desc", want: []string{"2026-01-01", "2026-01-04", "2026-01-02", "2026-01-03"}},
+		{name: "sort by rating ascending", sort: "rating", order: "asc", want: []string{"2026-01-03", "2026-01-02", "2026-01-04", "2026-01-01"}},
+		{name: "filter and sort", filter: "rating=4", sort: "date", order: "asc", want: []string{"2026-01-02", "2026-01-04"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := s.GetAll(testUserID, model.ListDiariesQuery{Filter: tt.filter, Sort: tt.sort, Order: tt.order})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, d := range list.Diaries {
+				got = append(got, d.Date)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dates = %v, want %v", got, tt.want)
+			}
+			if list.Pagination.Total != len(tt.want) {
+				t.Errorf("total = %d, want %d", list.Pagination.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestGetAllValidation(t *testing.T) {
+	s := newTestDiaryService(t)
+	cursor := ""
+	tests := []struct {
+		name    string
+		q       model.ListDiariesQuery
+		wantErr string
+	}{
+		{name: "dates reversed", q: model.ListDiariesQuery{StartDate: "2026-02-01", EndDate: "2026-01-01"}, wantErr: "start_date must be on or before end_date"},
+		{name: "cursor with another sort", q: model.ListDiariesQuery{Cursor: &cursor, Sort: "rating"}, wantErr: "cursor can only be used with sort=date and order=desc"},
+		{name: "cursor with offset", q: model.ListDiariesQuery{Cursor: &cursor, Offset: 10}, wantErr: "offset cannot be combined with cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GetAll(testUserID, tt.q)
+			if !errors.Is(err, ErrValidation) || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import "errors"
+
+// エラーの種類。ハンドラーはこれらを HTTP ステータスに対応付ける。
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Error はクライアントにそのまま返せるサービスエラー。
+// errors.Is(err, ErrNotFound) のように種類で判定できる。
+type Error struct {
+	Kind    error
+	Code    string // API_SPEC.md のエラーコード
+	Message string
+	// Details はフィールド名（JSON名）ごとのエラー内容（任意）
+	Details map[string]string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func notFound(message string) error {
+	return &Error{Kind: ErrNotFound, Code: "NOT_FOUND", Message: message}
+}
+
+func conflict(code, message string) error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+func validation(message string) error {
+	return &Error{Kind: ErrValidation, Code: "VALIDATION_ERROR", Message: message}
+}
+
+// fieldValidation はリクエストのバインド時の検証と同じ形（Details 付き）のバリデーションエラーを返す
+func fieldValidation(field, message string) error {
+	return &Error{Kind: ErrValidation, Code: "VALIDATION_ERROR", Message: "Validation failed", Details: map[string]string{field: message}}
+}
+
+func unauthorized(code, message string) error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+const (
+	// maxFilterConditions は絞り込み式に書ける条件の数の上限
+	maxFilterConditions = 20
+	// sleepTimePivot は就寝時刻を比べる際の1日の始まり。
+	// 就寝時刻は 12:00〜翌 11:59 の順に並べるため、00:30 は 23:00 より遅い。
+	sleepTimePivot = "12:00"
+)
+
+// filterFields は絞り込み式で使える項目
+var filterFields = map[string]repository.FilterField{
+	"date":         repository.FilterDate,
+	"rating":       repository.FilterRating,
+	"progress":     repository.FilterProgress,
+	"wake_up_time": repository.FilterWakeUpTime,
+	"sleep_time":   repository.FilterSleepTime,
+	"tag":          repository.FilterTag,
+}
+
+// filterOps は比較演算子。長いものから照合する。":" は "=" と同じ。
+var filterOps = []struct {
+	text string
+	op   repository.FilterOp
+}{
+	{">=", repository.FilterGe},
+	{"<=", repository.FilterLe},
+	{"!=", repository.FilterNe},
+	{">", repository.FilterGt},
+	{"<", repository.FilterLt},
+	{"=", repository.FilterEq},
+	{":", repository.FilterEq},
+}
+
+// filterParser は絞り込み式の再帰下降パーサー。
+//
+//	expr       = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" expr ")" | comparison
+//	comparison = field op value
+//
+// キーワードと項目名は大文字・小文字を区別しない。値は空白・括弧を含む場合 "..." で囲む。
+type filterParser struct {
+	input      []rune
+	pos        int
+	conditions int
+}
+
+// parseDiaryFilter は絞り込み式を解析する。空の場合は nil を返す。
+func parseDiaryFilter(input string) (*repository.FilterExpr, error) {
+	p := &filterParser{input: []rune(input)}
+	p.skipSpaces()
+	if p.eof() {
+		return nil, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.eof() {
+		if p.input[p.pos] == ')' {
+			return nil, p.errorf(`unexpected ")"`)
+		}
+		return nil, p.errorf("expected AND or OR, got %q", p.peekWord())
+	}
+	return &expr, nil
+}
+
+func (p *filterParser) parseOr() (repository.FilterExpr, error) {
+	return p.parseBinary(repository.FilterOr, p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (repository.FilterExpr, error) {
+	return p.parseBinary(repository.FilterAnd, p.parseUnary)
+}
+
+// parseBinary は keyword で区切られた operand の並びを1つの式にまとめる
+func (p *filterParser) parseBinary(keyword repository.FilterOp, operand func() (repository.FilterExpr, error)) (repository.FilterExpr, error) {
+	first, err := operand()
+	if err != nil {
+		return repository.FilterExpr{}, err
+	}
+	operands := []repository.FilterExpr{first}
+	for p.acceptKeyword(string(keyword)) {
+		next, err := operand()
+		if err != nil {
+			return repository.FilterExpr{}, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return repository.FilterExpr{Op: keyword, Operands: operands}, nil
+}
+
+func (p *filterParser) parseUnary() (repository.FilterExpr, error) {
+	p.skipSpaces()
+	if p.eof() {
+		return repository.FilterExpr{}, p.errorf("expected a condition")
+	}
+
+	if p.acceptKeyword(string(repository.FilterNot)) {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return repository.FilterExpr{}, err
+		}
+		return repository.FilterExpr{Op: repository.FilterNot, Operands: []repository.FilterExpr{operand}}, nil
+	}
+
+	if p.input[p.pos] == '(' {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return repository.FilterExpr{}, err
+		}
+		p.skipSpaces()
+		if p.eof() || p.input[p.pos] != ')' {
+			return repository.FilterExpr{}, p.errorf(`expected ")"`)
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (repository.FilterExpr, error) {
+	start := p.pos
+	for !p.eof() && (unicode.IsLetter(p.input[p.pos]) || p.input[p.pos] == '_') {
+		p.pos++
+	}
+	name := strings.ToLower(string(p.input[start:p.pos]))
+	if name == "" {
+		return repository.FilterExpr{}, p.errorf("expected a field name, got %q", p.peekWord())
+	}
+	field, ok := filterFields[name]
+	if !ok {
+		p.pos = start
+		return repository.FilterExpr{}, p.errorf("unknown field %q (use date, rating, progress, wake_up_time, sleep_time or tag)", name)
+	}
+
+	p.skipSpaces()
+	opStart := p.pos
+	op, opText, ok := p.acceptOp()
+	if !ok {
+		return repository.FilterExpr{}, p.errorf("expected an operator (: = != < <= > >=) after %s", name)
+	}
+
+	p.skipSpaces()
+	valueStart := p.pos
+	raw, err := p.parseValue()
+	if err != nil {
+		return repository.FilterExpr{}, err
+	}
+
+	p.conditions++
+	if p.conditions > maxFilterConditions {
+		return repository.FilterExpr{}, validation(fmt.Sprintf("filter: too many conditions (max %d)", maxFilterConditions))
+	}
+
+	expr, msg := filterComparison(field, op, raw)
+	if msg == errEqualityOnly {
+		p.pos = opStart
+		return repository.FilterExpr{}, p.errorf("%s supports only : = != (got %s)", name, opText)
+	}
+	if msg != "" {
+		p.pos = valueStart
+		return repository.FilterExpr{}, p.errorf("%s %s (got %q)", name, msg, raw)
+	}
+	return expr, nil
+}
+
+// parseValue は比較の値を読む。"..." の中では \" と \\ を使える。
+func (p *filterParser) parseValue() (string, error) {
+	if p.eof() {
+		return "", p.errorf("expected a value")
+	}
+
+	if p.input[p.pos] == '"' {
+		start := p.pos
+		p.pos++
+		var b strings.Builder
+		for !p.eof() {
+			r := p.input[p.pos]
+			p.pos++
+			switch {
+			case r == '"':
+				return b.String(), nil
+			case r == '\\' && !p.eof():
+				b.WriteRune(p.input[p.pos])
+				p.pos++
+			default:
+				b.WriteRune(r)
+			}
+		}
+		p.pos = start
+		return "", p.errorf("unterminated quoted value")
+	}
+
+	start := p.pos
+	for !p.eof() && !unicode.IsSpace(p.input[p.pos]) && p.input[p.pos] != '(' && p.input[p.pos] != ')' {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("expected a value")
+	}
+	return string(p.input[start:p.pos]), nil
+}
+
+func (p *filterParser) acceptOp() (repository.FilterOp, string, bool) {
+	rest := string(p.input[p.pos:])
+	for _, o := range filterOps {
+		if strings.HasPrefix(rest, o.text) {
+			p.pos += utf8.RuneCountInString(o.text)
+			return o.op, o.text, true
+		}
+	}
+	return "", "", false
+}
+
+// acceptKeyword は次の語が keyword（大文字・小文字を区別しない）の場合に読み進める
+func (p *filterParser) acceptKeyword(keyword string) bool {
+	p.skipSpaces()
+	end := p.pos + len(keyword)
+	if end > len(p.input) || !strings.EqualFold(string(p.input[p.pos:end]), keyword) {
+		return false
+	}
+	// "ANDROID" のような語の一部は keyword として扱わない
+	if end < len(p.input) && (unicode.IsLetter(p.input[end]) || p.input[end] == '_') {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *filterParser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *filterParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+// peekWord はエラーメッセージ用に次の語を返す
+func (p *filterParser) peekWord() string {
+	end := p.pos
+	for end < len(p.input) && !unicode.IsSpace(p.input[end]) {
+		end++
+	}
+	return string(p.input[p.pos:end])
+}
+
+// errorf は現在位置（1始まりの文字数）を付けたバリデーションエラーを返す
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return validation(fmt.Sprintf("filter: %s at position %d", fmt.Sprintf(format, args...), p.pos+1))
+}
+
+// errEqualityOnly は大小を比べられない項目に大小の比較を指定した場合の理由
+const errEqualityOnly = "supports only equality"
+
+// filterComparison は値を項目の形式で検証し、比較の式にする。不正な場合はその理由を返す。
+func filterComparison(field repository.FilterField, op repository.FilterOp, raw string) (repository.FilterExpr, string) {
+	expr := repository.FilterExpr{Op: op, Field: field}
+
+	switch field {
+	case repository.FilterDate:
+		if _, err := time.Parse("2006-01-02", raw); err != nil {
+			return expr, "must be a date in YYYY-MM-DD format"
+		}
+		expr.Value = raw
+	case repository.FilterRating:
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 || v > 5 {
+			return expr, "must be an integer from 1 to 5"
+		}
+		expr.Value = v
+	case repository.FilterProgress:
+		if op != repository.FilterEq && op != repository.FilterNe {
+			return expr, errEqualityOnly
+		}
+		v := strings.ToUpper(raw)
+		if v != "A" && v != "B" && v != "C" {
+			return expr, "must be one of A, B, C"
+		}
+		expr.Value = v
+	case repository.FilterWakeUpTime, repository.FilterSleepTime:
+		m, ok := parseClock(raw)
+		if !ok {
+			return expr, "must be a time in HH:MM format"
+		}
+		expr.Value = formatClock(m)
+		if field == repository.FilterSleepTime {
+			return sleepTimeComparison(op, expr.Value.(string)), ""
+		}
+	case repository.FilterTag:
+		if op != repository.FilterEq && op != repository.FilterNe {
+			return expr, errEqualityOnly
+		}
+		name := normalizeTagName(raw)
+		if name == "" {
+			return expr, "must not be blank"
+		}
+		expr.Value = name
+	}
+	return expr, ""
+}
+
+// sleepTimeComparison は就寝時刻の大小の比較を、0 時をまたいでも正しく比べられる条件に直す。
+// sleepTimePivot より前の時刻は翌日の深夜として扱う（sleep_time<23:00 は 12:00〜22:59 に一致し、00:30 には一致しない）。
+func sleepTimeComparison(op repository.FilterOp, value string) repository.FilterExpr {
+	cmp := func(op repository.FilterOp, v string) repository.FilterExpr {
+		return repository.FilterExpr{Op: op, Field: repository.FilterSleepTime, Value: v}
+	}
+	evening := cmp(repository.FilterGe, sleepTimePivot)
+	night := cmp(repository.FilterLt, sleepTimePivot)
+	both := func(logical repository.FilterOp, a, b repository.FilterExpr) repository.FilterExpr {
+		return repository.FilterExpr{Op: logical, Operands: []repository.FilterExpr{a, b}}
+	}
+
+	afterMidnight := value < sleepTimePivot
+	switch op {
+	case repository.FilterLt, repository.FilterLe:
+		if afterMidnight {
+			return both(repository.FilterOr, evening, cmp(op, value))
+		}
+		return both(repository.FilterAnd, evening, cmp(op, value))
+	case repository.FilterGt, repository.FilterGe:
+		if afterMidnight {
+			return both(repository.FilterAnd, night, cmp(op, value))
+		}
+		return both(repository.FilterOr, night, cmp(op, value))
+	}
+	return cmp(op, value)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+// exprString は式を rating>=4 や AND(a, b) の形の文字列にする
+func exprString(e repository.FilterExpr) string {
+	if e.Field != "" {
+		return fmt.Sprintf("%s%s%v", e.Field, e.Op, e.Value)
+	}
+	operands := make([]string, len(e.Operands))
+	for i, o := range e.Operands {
+		operands[i] = exprString(o)
+	}
+	return fmt.Sprintf("%s(%s)", e.Op, strings.Join(operands, ", "))
+}
+
+func TestParseDiaryFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "empty", input: "", want: ""},
+		{name: "spaces only", input: "   ", want: ""},
+		{name: "single comparison", input: "rating>=4", want: "rating>=4"},
+		{name: "colon is equality", input: "progress:a", want: "progress=A"},
+		{name: "keywords are case-insensitive", input: "rating>=4 and Progress:A", want: "AND(rating>=4, progress=A)"},
+		{name: "AND binds tighter than OR", input: "rating=1 OR rating=2 AND progress=A", want: "OR(rating=1, AND(rating=2, progress=A))"},
+		{name: "parentheses", input: "(rating=1 OR rating=2) AND progress=A", want: "AND(OR(rating=1, rating=2), progress=A)"},
+		{name: "NOT", input: "NOT NOT tag=work", want: "NOT(NOT(tag=work))"},
+		{name: "quoted value", input: `tag:"night owl" OR NOT tag=work`, want: "OR(tag=night owl, NOT(tag=work))"},
+		{name: "escaped quote", input: `tag="a \"b\""`, want: `tag=a "b"`},
+		{name: "keyword prefix is a value", input: "tag=ANDROID", want: "tag=android"},
+		{name: "date", input: "date>=2026-01-01 AND date<2026-02-01", want: "AND(date>=2026-01-01, date<2026-02-01)"},
+		{name: "wake-up time", input: "wake_up_time<=06:30", want: "wake_up_time<=06:30"},
+		{name: "sleep time before midnight", input: "sleep_time<23:00", want: "AND(sleep_time>=12:00, sleep_time<23:00)"},
+		{name: "sleep time after midnight", input: "sleep_time<01:00", want: "OR(sleep_time>=12:00, sleep_time<01:00)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parseDiaryFilter(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := ""
+			if expr != nil {
+				got = exprString(*expr)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDiaryFilterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "missing value", input: "rating>=", wantErr: "filter: expected a value at position 9"},
+		{name: "unknown field", input: "foo=1", wantErr: `filter: unknown field "foo" (use date, rating, progress, wake_up_time, sleep_time or tag) at position 1`},
+		{name: "missing operator", input: "rating 4", wantErr: "filter: expected an operator (: = != < <= > >=) after rating at position 8"},
+		{name: "trailing AND", input: "rating>=4 AND", wantErr: "filter: expected a condition at position 14"},
+		{name: "missing field", input: "rating=4 AND >3", wantErr: `filter: expected a field name, got ">3" at position 14`},
+		{name: "unclosed parenthesis", input: "(rating=4", wantErr: `filter: expected ")" at position 10`},
+		{name: "unexpected parenthesis", input: "rating=4)", wantErr: `filter: unexpected ")" at position 9`},
+		{name: "missing keyword", input: "rating=4 rating=5", wantErr: `filter: expected AND or OR, got "rating=5" at position 10`},
+		{name: "ordering on progress", input: "progress>A", wantErr: "filter: progress supports only : = != (got >) at position 9"},
+		{name: "ordering on tag", input: "tag<=work", wantErr: "filter: tag supports only : = != (got <=) at position 4"},
+		{name: "rating out of range", input: "rating=6", wantErr: `filter: rating must be an integer from 1 to 5 (got "6") at position 8`},
+		{name: "invalid date", input: "date=2026-13-01", wantErr: `filter: date must be a date in YYYY-MM-DD format (got "2026-13-01") at position 6`},
+		{name: "invalid time", input: "sleep_time<25:00", wantErr: `filter: sleep_time must be a time in HH:MM format (got "25:00") at position 12`},
+		{name: "invalid progress", input: "progress=D", wantErr: `filter: progress must be one of A, B, C (got "D") at position 10`},
+		{name: "blank tag", input: `tag=" "`, wantErr: `filter: tag must not be blank (got " ") at position 5`},
+		{name: "unterminated quote", input: `tag="work`, wantErr: "filter: unterminated quoted value at position 5"},
+		{name: "position counts characters", input: "tag=日記 AND foo=1", wantErr: `filter: unknown field "foo" (use date, rating, progress, wake_up_time, sleep_time or tag) at position 12`},
+		{name: "too many conditions", input: strings.Repeat("rating=1 OR ", maxFilterConditions) + "rating=1", wantErr: "filter: too many conditions (max 20)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDiaryFilter(tt.input)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("error = %q, want %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+// evalSleepTime は sleep_time の比較だけからなる式を就寝時刻 v で評価する
+func evalSleepTime(e repository.FilterExpr, v string) bool {
+	switch e.Op {
+	case repository.FilterAnd:
+		return evalSleepTime(e.Operands[0], v) && evalSleepTime(e.Operands[1], v)
+	case repository.FilterOr:
+		return evalSleepTime(e.Operands[0], v) || evalSleepTime(e.Operands[1], v)
+	}
+	value := e.Value.(string)
+	switch e.Op {
+	case repository.FilterEq:
+		return v == value
+	case repository.FilterNe:
+		return v != value
+	case repository.FilterLt:
+		return v < value
+	case repository.FilterLe:
+		return v <= value
+	case repository.FilterGt:
+		return v > value
+	default:
+		return v >= value
+	}
+}
+
+func TestSleepTimeComparison(t *testing.T) {
+	tests := []struct {
+		op       repository.FilterOp
+		value    string
+		match    []string
+		notMatch []string
+	}{
+		{op: repository.FilterLt, value: "23:00", match: []string{"12:00", "22:59"}, notMatch: []string{"23:00", "23:30", "00:30", "11:59"}},
+		{op: repository.FilterLe, value: "01:00", match: []string{"22:00", "00:30", "01:00"}, notMatch: []string{"01:30", "11:59"}},
+		{op: repository.FilterGt, value: "23:00", match: []string{"23:30", "00:30", "11:59"}, notMatch: []string{"23:00", "22:00", "12:00"}},
+		{op: repository.FilterGe, value: "00:30", match: []string{"00:30", "03:00", "11:59"}, notMatch: []string{"23:30", "00:00", "12:00"}},
+		{op: repository.FilterGe, value: "12:00", match: []string{"12:00", "23:59", "00:00", "11:59"}},
+		{op: repository.FilterEq, value: "23:00", match: []string{"23:00"}, notMatch: []string{"22:00", "00:00"}},
+		{op: repository.FilterNe, value: "23:00", match: []string{"22:00", "00:00"}, notMatch: []string{"23:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s%s", tt.op, tt.value), func(t *testing.T) {
+			expr := sleepTimeComparison(tt.op, tt.value)
+			for _, v := range tt.match {
+				if !evalSleepTime(expr, v) {
+					t.Errorf("%s should match %s", exprString(expr), v)
+				}
+			}
+			for _, v := range tt.notMatch {
+				if evalSleepTime(expr, v) {
+					t.Errorf("%s should not match %s", exprString(expr), v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+// maxHeatmapIntensity はヒートマップの濃さの段階数（アプリの getHeatMapColor の 0〜5 に合わせる）
+const maxHeatmapIntensity = 5
+
+// progressIntensity は進捗ごとの濃さ
+var progressIntensity = map[string]int{"A": 5, "B": 3, "C": 1}
+
+// sleepIntensity は睡眠時間の目標に対する割合から濃さを求める
+func sleepIntensity(minutes, target int) int {
+	ratio := float64(minutes) / float64(target)
+	switch {
+	case ratio >= 1:
+		return 5
+	case ratio >= 0.9:
+		return 4
+	case ratio >= 0.75:
+		return 3
+	case ratio >= 0.6:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// GetYearHeatmap は year 年の全日分のセルと月ごとの集計を返す。
+// metric（rating / progress / sleep / 記録項目の ID、空の場合は rating）で各セルの濃さを決める。
+func (s *DiaryService) GetYearHeatmap(userID string, year int, metric string) (*model.YearHeatmap, error) {
+	if metric == "" {
+		metric = "rating"
+	}
+	if year < 1900 || year > 9999 {
+		return nil, validation("year must be between 1900 and 9999")
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, -1)
+	startDate, endDate := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	// 記録項目の場合はその年の値と、範囲が定義されていない場合の最小・最大
+	var custom *model.Metric
+	var values map[string]map[string]float64
+	var lo, hi float64
+	if metric != "rating" && metric != "progress" && metric != "sleep" {
+		definitions, err := s.metricDefinitions(userID)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := definitions[metric]
+		if !ok {
+			return nil, validation("metric must be one of rating, progress, sleep or a metric ID")
+		}
+		custom = &m
+		values, err = s.metrics.ListValues(userID, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		first := true
+		for _, byMetric := range values {
+			if v, ok := byMetric[m.ID]; ok {
+				if first || v < lo {
+					lo = v
+				}
+				if first || v > hi {
+					hi = v
+				}
+				first = false
+			}
+		}
+	}
+
+	// 1月1日の睡眠時間を求めるため前日分から取得する
+	diaries, err := s.repo.GetRange(userID, addDays(startDate, -1), endDate)
+	if err != nil {
+		return nil, err
+	}
+	byDate := indexByDate(diaries)
+
+	target := model.DefaultSleepTargetMinutes
+	if metric == "sleep" {
+		settings, err := s.users.GetSettings(userID)
+		if err != nil {
+			return nil, err
+		}
+		if settings != nil {
+			target = settings.SleepTargetMinutes
+		}
+	}
+
+	heatmap := &model.YearHeatmap{
+		Year:         year,
+		Metric:       metric,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		MaxIntensity: maxHeatmapIntensity,
+		Cells:        make([]model.HeatmapCell, 0, end.YearDay()),
+		Months:       make([]model.HeatmapMonth, 12),
+	}
+	ratingSums := make([]int, 12)
+	totalRating := 0
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		month := &heatmap.Months[day.Month()-1]
+		month.Month = int(day.Month())
+		month.TotalDays++
+
+		cell := model.HeatmapCell{Date: date, Weekday: int(day.Weekday())}
+		if d, ok := byDate[date]; ok {
+			rating, progress := d.Rating, d.Progress
+			cell.Recorded = true
+			cell.Rating = &rating
+			cell.Progress = &progress
+			cell.HasMemo = strings.TrimSpace(d.Memo) != ""
+			if prev, ok := byDate[addDays(date, -1)]; ok {
+				if duration, ok := sleepDuration(prev.SleepTime, d.WakeUpTime); ok {
+					cell.SleepDurationMinutes = &duration
+				}
+			}
+
+			switch metric {
+			case "rating":
+				cell.Intensity = rating
+			case "progress":
+				cell.Intensity = progressIntensity[progress]
+			case "sleep":
+				if cell.SleepDurationMinutes != nil {
+					cell.Intensity = sleepIntensity(*cell.SleepDurationMinutes, target)
+				}
+			default:
+				if v, ok := values[date][custom.ID]; ok {
+					cell.Value = &v
+					cell.Intensity = metricIntensity(*custom, v, lo, hi)
+				}
+			}
+
+			month.RecordedDays++
+			ratingSums[day.Month()-1] += rating
+			totalRating += rating
+		}
+		heatmap.Cells = append(heatmap.Cells, cell)
+	}
+
+	for i := range heatmap.Months {
+		if n := heatmap.Months[i].RecordedDays; n > 0 {
+			heatmap.Months[i].AverageRating = float64(ratingSums[i]) / float64(n)
+		}
+		heatmap.Summary.TotalDays += heatmap.Months[i].TotalDays
+		heatmap.Summary.RecordedDays += heatmap.Months[i].RecordedDays
+	}
+	if heatmap.Summary.RecordedDays > 0 {
+		heatmap.Summary.AverageRating = float64(totalRating) / float64(heatmap.Summary.RecordedDays)
+	}
+
+	return heatmap, nil
+}
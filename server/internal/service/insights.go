@@ -0,0 +1,355 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+const (
+	// minCorrelationSamples は相関を所見として扱うのに必要な最小の日数
+	minCorrelationSamples = 10
+	// minComparisonSamples は比較の各グループに必要な最小の日数
+	minComparisonSamples = 5
+)
+
+// insightMetric は相関を求める指標
+type insightMetric struct {
+	key  string
+	high string // 値が大きい側の表現
+	low  string // 値が小さい側の表現
+}
+
+var insightMetrics = []insightMetric{
+	{key: "sleep_duration", high: "前夜の睡眠時間が長い", low: "前夜の睡眠時間が短い"},
+	{key: "bedtime", high: "前夜の就寝が遅い", low: "前夜の就寝が早い"},
+	{key: "wake_time", high: "起床が遅い", low: "起床が早い"},
+	{key: "progress", high: "進捗が良い", low: "進捗が悪い"},
+	{key: "rating", high: "評価が高い", low: "評価が低い"},
+}
+
+var strengthLabels = map[string]string{"weak": "弱い", "moderate": "中程度の", "strong": "強い"}
+
+// insightDay は1日分の指標（値がない場合は nil）。
+// 睡眠時間と就寝時刻はその日の前夜（前日の sleep_time）のもの。
+type insightDay map[string]*float64
+
+// GetInsights は期間内の睡眠・進捗・評価の相関と、就寝時刻や睡眠時間で分けた評価の比較を返す。
+// 期間の指定方法は GetStatistics と同じ。
+func (s *DiaryService) GetInsights(userID, tz, period, startDate, endDate string) (*model.Insights, error) {
+	startDate, endDate, period, err := s.statisticsPeriod(userID, tz, period, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	diaries, err := s.repo.GetRange(userID, addDays(startDate, -1), endDate)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.users.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	target := model.DefaultSleepTargetMinutes
+	if settings != nil {
+		target = settings.SleepTargetMinutes
+	}
+
+	byDate := indexByDate(diaries)
+	var inRange []model.Diary
+	for _, d := range diaries {
+		if d.Date >= startDate {
+			inRange = append(inRange, d)
+		}
+	}
+
+	// 時刻は 0 時をまたぐため、円周平均を中心に前後12時間の直線上の値に直す
+	wakeCenter, bedCenter := clockCenter(inRange, func(d model.Diary) string { return d.WakeUpTime }),
+		clockCenter(diaries, func(d model.Diary) string { return d.SleepTime })
+
+	days := make([]insightDay, 0, len(inRange))
+	for _, d := range inRange {
+		day := insightDay{
+			"rating":   floatPtr(float64(d.Rating)),
+			"progress": floatPtr(progressScores[d.Progress]),
+		}
+		if m, ok := parseClock(d.WakeUpTime); ok {
+			day["wake_time"] = floatPtr(unwrapClock(m, wakeCenter))
+		}
+		if prev, ok := byDate[addDays(d.Date, -1)]; ok {
+			if m, ok := parseClock(prev.SleepTime); ok {
+				day["bedtime"] = floatPtr(unwrapClock(m, bedCenter))
+			}
+			if duration, ok := sleepDuration(prev.SleepTime, d.WakeUpTime); ok {
+				day["sleep_duration"] = floatPtr(float64(duration))
+			}
+		}
+		days = append(days, day)
+	}
+
+	insights := &model.Insights{
+		Period:        period,
+		PeriodStart:   startDate,
+		PeriodEnd:     endDate,
+		TotalEntries:  len(inRange),
+		MinSampleSize: minCorrelationSamples,
+		Correlations:  []model.Correlation{},
+		Findings:      []string{},
+	}
+
+	for i, x := range insightMetrics {
+		for _, y := range insightMetrics[i+1:] {
+			c := correlate(days, x.key, y.key)
+			insights.Correlations = append(insights.Correlations, c)
+			if c.Significant && c.Strength != "none" {
+				insights.Findings = append(insights.Findings, correlationFinding(c, x, y))
+			}
+		}
+	}
+
+	insights.Comparisons = []model.GroupComparison{
+		compareRatings(days, "bedtime_before_midnight", "前夜0時より前に就寝した日", "前夜0時以降に就寝した日", func(day insightDay) (bool, bool) {
+			if day["bedtime"] == nil {
+				return false, false
+			}
+			m := ((int(*day["bedtime"]) % minutesPerDay) + minutesPerDay) % minutesPerDay
+			// 18:00〜23:59 を0時より前とみなす
+			return m >= 18*60, true
+		}),
+		compareRatings(days, "sleep_target_met", fmt.Sprintf("前夜の睡眠が%d分以上の日", target), fmt.Sprintf("前夜の睡眠が%d分未満の日", target), func(day insightDay) (bool, bool) {
+			if day["sleep_duration"] == nil {
+				return false, false
+			}
+			return *day["sleep_duration"] >= float64(target), true
+		}),
+	}
+	for _, c := range insights.Comparisons {
+		if f, ok := comparisonFinding(c); ok {
+			insights.Findings = append(insights.Findings, f)
+		}
+	}
+
+	if len(insights.Findings) == 0 {
+		if len(inRange) < minCorrelationSamples {
+			insights.Findings = append(insights.Findings,
+				fmt.Sprintf("記録が%d日分のため、傾向を判断するにはデータが足りません（%d日以上必要）", len(inRange), minCorrelationSamples))
+		} else {
+			insights.Findings = append(insights.Findings, "はっきりした傾向は見つかりませんでした")
+		}
+	}
+
+	return insights, nil
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func roundPtr(v float64) *float64 {
+	return floatPtr(math.Round(v*1000) / 1000)
+}
+
+// clockCenter は時刻の円周平均を分で返す（求まらない場合は 0 時）
+func clockCenter(diaries []model.Diary, clock func(model.Diary) string) int {
+	times := make([]string, len(diaries))
+	for i, d := range diaries {
+		times[i] = clock(d)
+	}
+	mean, _, ok := clockStats(times)
+	if !ok {
+		return 0
+	}
+	m, _ := parseClock(mean)
+	return m
+}
+
+// unwrapClock は minutes を center の前後12時間に収まる連続な値に直す
+func unwrapClock(minutes, center int) float64 {
+	diff := ((minutes-center+minutesPerDay/2)%minutesPerDay+minutesPerDay)%minutesPerDay - minutesPerDay/2
+	return float64(center + diff)
+}
+
+// correlate は x と y が両方ある日について Pearson と Spearman の相関係数を求める
+func correlate(days []insightDay, x, y string) model.Correlation {
+	var xs, ys []float64
+	for _, day := range days {
+		if day[x] != nil && day[y] != nil {
+			xs = append(xs, *day[x])
+			ys = append(ys, *day[y])
+		}
+	}
+
+	c := model.Correlation{X: x, Y: y, SampleSize: len(xs), Strength: "none"}
+	if r, ok := pearson(xs, ys); ok {
+		c.Pearson = roundPtr(r)
+	}
+	rho, ok := pearson(ranks(xs), ranks(ys))
+	if !ok {
+		return c
+	}
+	c.Spearman = roundPtr(rho)
+	c.Strength = correlationStrength(rho)
+
+	if n := len(xs); n > 3 {
+		// Fisher の z 変換による 95% 信頼区間
+		z := math.Atanh(math.Max(math.Min(rho, 0.999999), -0.999999))
+		se := 1 / math.Sqrt(float64(n-3))
+		low, high := math.Tanh(z-1.96*se), math.Tanh(z+1.96*se)
+		c.CILow, c.CIHigh = roundPtr(low), roundPtr(high)
+		c.Significant = n >= minCorrelationSamples && (low > 0 || high < 0)
+	}
+
+	return c
+}
+
+// pearson は Pearson の積率相関係数を返す。標本が2未満か値が一定の場合は ok=false。
+func pearson(xs, ys []float64) (float64, bool) {
+	n := len(xs)
+	if n < 2 {
+		return 0, false
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
+
+// ranks は値の順位を返す（同順位は平均順位）
+func ranks(values []float64) []float64 {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	r := make([]float64, len(values))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && values[idx[j+1]] == values[idx[i]] {
+			j++
+		}
+		avg := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			r[idx[k]] = avg
+		}
+		i = j + 1
+	}
+	return r
+}
+
+func correlationStrength(r float64) string {
+	switch a := math.Abs(r); {
+	case a >= 0.5:
+		return "strong"
+	case a >= 0.3:
+		return "moderate"
+	case a >= 0.1:
+		return "weak"
+	default:
+		return "none"
+	}
+}
+
+func correlationFinding(c model.Correlation, x, y insightMetric) string {
+	effect := y.high
+	if *c.Spearman < 0 {
+		effect = y.low
+	}
+	return fmt.Sprintf("%s日ほど%s傾向があります（%s相関 ρ=%.2f, n=%d）",
+		x.high, effect, strengthLabels[c.Strength], *c.Spearman, c.SampleSize)
+}
+
+// compareRatings は split で2グループに分けた日の評価の平均を比べる。
+// split は (A グループかどうか, 判定できたかどうか) を返す。
+func compareRatings(days []insightDay, name, labelA, labelB string, split func(insightDay) (bool, bool)) model.GroupComparison {
+	var a, b []float64
+	for _, day := range days {
+		isA, ok := split(day)
+		if !ok {
+			continue
+		}
+		if isA {
+			a = append(a, *day["rating"])
+		} else {
+			b = append(b, *day["rating"])
+		}
+	}
+
+	c := model.GroupComparison{
+		Name: name,
+		A:    model.ComparisonGroup{Label: labelA, SampleSize: len(a)},
+		B:    model.ComparisonGroup{Label: labelB, SampleSize: len(b)},
+	}
+	meanA, varA := meanVariance(a)
+	meanB, varB := meanVariance(b)
+	if len(a) > 0 {
+		c.A.AverageRating = floatPtr(math.Round(meanA*100) / 100)
+	}
+	if len(b) > 0 {
+		c.B.AverageRating = floatPtr(math.Round(meanB*100) / 100)
+	}
+	if len(a) > 0 && len(b) > 0 {
+		diff := meanA - meanB
+		c.Difference = floatPtr(math.Round(diff*100) / 100)
+
+		// 平均の差がその標準誤差の 1.96 倍を超える場合のみ有意とみなす（Welch の近似）
+		if len(a) >= minComparisonSamples && len(b) >= minComparisonSamples {
+			se := math.Sqrt(varA/float64(len(a)) + varB/float64(len(b)))
+			c.Significant = se > 0 && math.Abs(diff) > 1.96*se
+		}
+	}
+	return c
+}
+
+// meanVariance は平均と不偏分散を返す
+func meanVariance(values []float64) (float64, float64) {
+	n := len(values)
+	if n == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(n)
+	if n < 2 {
+		return mean, 0
+	}
+	var ss float64
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	return mean, ss / float64(n-1)
+}
+
+// comparisonFinding は有意な差がある比較について所見を返す
+func comparisonFinding(c model.GroupComparison) (string, bool) {
+	if !c.Significant {
+		return "", false
+	}
+
+	higher, lower := c.A, c.B
+	if *c.Difference < 0 {
+		higher, lower = c.B, c.A
+	}
+	return fmt.Sprintf("%sの評価は平均%.2fで、%s（平均%.2f）より高めです（n=%d / %d）",
+		higher.Label, *higher.AverageRating, lower.Label, *lower.AverageRating, higher.SampleSize, lower.SampleSize), true
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []float64
+	}{
+		{name: "empty", values: nil, want: []float64{}},
+		{name: "distinct", values: []float64{10, 30, 20}, want: []float64{1, 3, 2}},
+		{name: "ties share the average rank", values: []float64{5, 1, 5, 3}, want: []float64{3.5, 1, 3.5, 2}},
+		{name: "all equal", values: []float64{2, 2, 2}, want: []float64{2, 2, 2}},
+		{name: "negative values", values: []float64{-1.5, 0, -1.5}, want: []float64{1.5, 3, 1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ranks(tt.values)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPearson(t *testing.T) {
+	tests := []struct {
+		name   string
+		xs, ys []float64
+		want   float64
+		wantOK bool
+	}{
+		{name: "perfect positive", xs: []float64{1, 2, 3}, ys: []float64{2, 4, 6}, want: 1, wantOK: true},
+		{name: "perfect negative", xs: []float64{1, 2, 3}, ys: []float64{3, 2, 1}, want: -1, wantOK: true},
+		{name: "uncorrelated", xs: []float64{1, 2, 3}, ys: []float64{1, 3, 1}, want: 0, wantOK: true},
+		{name: "partial", xs: []float64{1, 2, 3, 4, 5}, ys: []float64{2, 4, 5, 4, 5}, want: 6 / math.Sqrt(60), wantOK: true},
+		{name: "constant x", xs: []float64{2, 2, 2}, ys: []float64{1, 2, 3}, wantOK: false},
+		{name: "constant y", xs: []float64{1, 2, 3}, ys: []float64{4, 4, 4}, wantOK: false},
+		{name: "single sample", xs: []float64{1}, ys: []float64{1}, wantOK: false},
+		{name: "empty", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pearson(tt.xs, tt.ys)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	// y は x について単調だが線形ではない。x か y がない日は除く
+	var days []insightDay
+	for i := 1; i <= 10; i++ {
+		x, y := float64(i), float64(i*i)
+		days = append(days, insightDay{"x": &x, "y": &y})
+	}
+	days = append(days, insightDay{"x": floatPtr(100)}, insightDay{"y": floatPtr(-100)})
+
+	c := correlate(days, "x", "y")
+	if c.SampleSize != 10 {
+		t.Errorf("sample size = %d, want 10", c.SampleSize)
+	}
+	if c.Spearman == nil || *c.Spearman != 1 {
+		t.Errorf("spearman = %v, want 1", c.Spearman)
+	}
+	if c.Pearson == nil || *c.Pearson >= 1 || *c.Pearson < 0.9 {
+		t.Errorf("pearson = %v, want between 0.9 and 1", c.Pearson)
+	}
+	if c.Strength != "strong" || !c.Significant {
+		t.Errorf("strength = %s, significant = %v", c.Strength, c.Significant)
+	}
+
+	few := correlate(days[:4], "x", "y")
+	if few.Significant || few.CILow == nil {
+		t.Errorf("4 samples: significant = %v, ci_low = %v", few.Significant, few.CILow)
+	}
+	constant := correlate([]insightDay{{"x": floatPtr(1), "y": floatPtr(1)}, {"x": floatPtr(1), "y": floatPtr(2)}}, "x", "y")
+	if constant.Pearson != nil || constant.Spearman != nil || constant.Strength != "none" {
+		t.Errorf("constant x: %+v", constant)
+	}
+}
+
+func TestCorrelationStrength(t *testing.T) {
+	tests := []struct {
+		r    float64
+		want string
+	}{
+		{r: 0, want: "none"},
+		{r: 0.099, want: "none"},
+		{r: -0.1, want: "weak"},
+		{r: 0.3, want: "moderate"},
+		{r: -0.49, want: "moderate"},
+		{r: 0.5, want: "strong"},
+		{r: -1, want: "strong"},
+	}
+
+	for _, tt := range tests {
+		if got := correlationStrength(tt.r); got != tt.want {
+			t.Errorf("correlationStrength(%v) = %s, want %s", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestUnwrapClock(t *testing.T) {
+	tests := []struct {
+		name            string
+		minutes, center int
+		want            float64
+	}{
+		{name: "same side", minutes: 23 * 60, center: 23*60 + 30, want: 23 * 60},
+		{name: "after midnight", minutes: 30, center: 23*60 + 30, want: 24*60 + 30},
+		{name: "before midnight", minutes: 23*60 + 30, center: 0, want: -30},
+		{name: "morning", minutes: 7 * 60, center: 6 * 60, want: 7 * 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unwrapClock(tt.minutes, tt.center); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+var (
+	errMetricNotFound = notFound("Metric not found")
+	errMetricExists   = conflict("METRIC_ALREADY_EXISTS", "Metric with this name already exists")
+)
+
+type MetricService struct {
+	metrics repository.MetricRepository
+}
+
+func NewMetricService(metrics repository.MetricRepository) *MetricService {
+	return &MetricService{metrics: metrics}
+}
+
+func (s *MetricService) List(userID string) ([]model.Metric, error) {
+	metrics, err := s.metrics.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	if metrics == nil {
+		metrics = []model.Metric{}
+	}
+	return metrics, nil
+}
+
+// Get は該当する記録項目がない場合 ErrNotFound を返す。
+// UUID でない ID は PostgreSQL で型エラーになるため、問い合わせずに見つからない扱いにする。
+func (s *MetricService) Get(userID, id string) (*model.Metric, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errMetricNotFound
+	}
+	metric, err := s.metrics.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if metric == nil {
+		return nil, errMetricNotFound
+	}
+	return metric, nil
+}
+
+// Create は同じ名前の記録項目がある場合 ErrConflict を返す
+func (s *MetricService) Create(userID string, req model.CreateMetricRequest) (*model.Metric, error) {
+	now := time.Now()
+	metric := &model.Metric{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Type:      req.Type,
+		Unit:      strings.TrimSpace(req.Unit),
+		Min:       req.Min,
+		Max:       req.Max,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := validateMetric(metric); err != nil {
+		return nil, err
+	}
+
+	if err := s.metrics.Create(metric); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, errMetricExists
+		}
+		return nil, err
+	}
+
+	return s.Get(userID, metric.ID)
+}
+
+// Update は名前・単位・範囲を置き換える。既に記録した値は新しい範囲で検証し直さない。
+func (s *MetricService) Update(userID, id string, req model.UpdateMetricRequest) (*model.Metric, error) {
+	metric, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	metric.Name = strings.TrimSpace(req.Name)
+	metric.Unit = strings.TrimSpace(req.Unit)
+	metric.Min = req.Min
+	metric.Max = req.Max
+	metric.UpdatedAt = time.Now()
+	if err := validateMetric(metric); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.metrics.Update(metric)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, errMetricExists
+		}
+		return nil, err
+	}
+	if !updated {
+		return nil, errMetricNotFound
+	}
+
+	return s.Get(userID, id)
+}
+
+// Delete は記録項目と記録した値を削除する
+func (s *MetricService) Delete(userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return errMetricNotFound
+	}
+	deleted, err := s.metrics.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errMetricNotFound
+	}
+	return nil
+}
+
+// validateMetric は種類ごとの単位・範囲の制約を検証する
+func validateMetric(m *model.Metric) error {
+	if m.Name == "" {
+		return validation("name must not be blank")
+	}
+
+	switch m.Type {
+	case model.MetricTypeBoolean:
+		if m.Unit != "" || m.Min != nil || m.Max != nil {
+			return validation("boolean metrics cannot have unit, min or max")
+		}
+	case model.MetricTypeScale:
+		if m.Min == nil || m.Max == nil || !isInteger(*m.Min) || !isInteger(*m.Max) || *m.Min >= *m.Max {
+			return validation("scale metrics require integer min and max with min < max")
+		}
+	case model.MetricTypeDuration:
+		if m.Min != nil && *m.Min < 0 {
+			return validation("min of duration metrics must be 0 or greater")
+		}
+	}
+
+	if m.Min != nil && m.Max != nil && *m.Min > *m.Max {
+		return validation("min must be less than or equal to max")
+	}
+	return nil
+}
+
+func isInteger(v float64) bool {
+	return v == math.Trunc(v)
+}
+
+// metricValues は日記に指定された値を記録項目の定義で検証し、保存する値に変換する。
+// null の値は nil（値の削除）になる。boolean は 1 / 0 で保存する。
+func (s *DiaryService) metricValues(userID string, input map[string]interface{}) (map[string]*float64, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	definitions, err := s.metricDefinitions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]*float64, len(input))
+	for id, raw := range input {
+		metric, ok := definitions[id]
+		if !ok {
+			return nil, validation(fmt.Sprintf("metrics.%s is not a defined metric", id))
+		}
+		if raw == nil {
+			values[id] = nil
+			continue
+		}
+		value, msg := metricValue(metric, raw)
+		if msg != "" {
+			return nil, validation(fmt.Sprintf("metrics.%s (%s) %s", id, metric.Name, msg))
+		}
+		values[id] = &value
+	}
+	return values, nil
+}
+
+// metricValue は raw を metric の値として検証する。不正な場合はその理由を返す。
+func metricValue(metric model.Metric, raw interface{}) (float64, string) {
+	if metric.Type == model.MetricTypeBoolean {
+		b, ok := raw.(bool)
+		if !ok {
+			return 0, "must be true or false"
+		}
+		if b {
+			return 1, ""
+		}
+		return 0, ""
+	}
+
+	v, ok := raw.(float64)
+	if !ok {
+		return 0, "must be a number"
+	}
+	if (metric.Type == model.MetricTypeScale || metric.Type == model.MetricTypeDuration) && !isInteger(v) {
+		return 0, "must be an integer"
+	}
+	if metric.Type == model.MetricTypeDuration && v < 0 {
+		return 0, "must be 0 or greater"
+	}
+	if metric.Min != nil && v < *metric.Min {
+		return 0, fmt.Sprintf("must be %g or greater", *metric.Min)
+	}
+	if metric.Max != nil && v > *metric.Max {
+		return 0, fmt.Sprintf("must be %g or less", *metric.Max)
+	}
+	return v, ""
+}
+
+// metricDefinitions は記録項目を ID で引けるようにして返す
+func (s *DiaryService) metricDefinitions(userID string) (map[string]model.Metric, error) {
+	metrics, err := s.metrics.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]model.Metric, len(metrics))
+	for _, m := range metrics {
+		byID[m.ID] = m
+	}
+	return byID, nil
+}
+
+// withMetrics は日記に記録項目の値を設定する（値がない場合は空のオブジェクト）
+func (s *DiaryService) withMetrics(userID string, diaries []model.Diary) error {
+	if len(diaries) == 0 {
+		return nil
+	}
+	definitions, err := s.metricDefinitions(userID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(diaries))
+	for i, d := range diaries {
+		ids[i] = d.ID
+	}
+	values, err := s.metrics.ListForDiaries(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range diaries {
+		diaries[i].Metrics = map[string]interface{}{}
+		for id, v := range values[diaries[i].ID] {
+			if definitions[id].Type == model.MetricTypeBoolean {
+				diaries[i].Metrics[id] = v != 0
+			} else {
+				diaries[i].Metrics[id] = v
+			}
+		}
+	}
+	return nil
+}
+
+// metricStats は期間内の記録項目ごとの集計を作成順に返す
+func (s *DiaryService) metricStats(userID, startDate, endDate string) ([]model.MetricStat, error) {
+	metrics, err := s.metrics.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]model.MetricStat, len(metrics))
+	if len(metrics) == 0 {
+		return stats, nil
+	}
+
+	values, err := s.metrics.ListValues(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range metrics {
+		stat := model.MetricStat{MetricID: m.ID, Name: m.Name, Type: m.Type, Unit: m.Unit}
+		var lowest, highest float64
+		for _, byMetric := range values {
+			v, ok := byMetric[m.ID]
+			if !ok {
+				continue
+			}
+			if stat.TotalEntries == 0 || v < lowest {
+				lowest = v
+			}
+			if stat.TotalEntries == 0 || v > highest {
+				highest = v
+			}
+			stat.TotalEntries++
+			stat.Sum += v
+		}
+		if stat.TotalEntries > 0 {
+			stat.Average = average(stat.Sum, stat.TotalEntries)
+			stat.Sum = math.Round(stat.Sum*100) / 100
+			if m.Type != model.MetricTypeBoolean {
+				stat.Min, stat.Max = &lowest, &highest
+			}
+		}
+		stats[i] = stat
+	}
+	return stats, nil
+}
+
+// metricIntensity は値を記録項目の範囲（定義されていない場合は lo〜hi、duration の下限は 0）で
+// 1〜maxHeatmapIntensity に対応付ける
+func metricIntensity(metric model.Metric, v, lo, hi float64) int {
+	if metric.Type == model.MetricTypeBoolean {
+		if v != 0 {
+			return maxHeatmapIntensity
+		}
+		return 1
+	}
+	if metric.Type == model.MetricTypeDuration {
+		lo = 0
+	}
+	if metric.Min != nil {
+		lo = *metric.Min
+	}
+	if metric.Max != nil {
+		hi = *metric.Max
+	}
+	if hi <= lo {
+		return maxHeatmapIntensity
+	}
+
+	intensity := 1 + int(math.Round((v-lo)/(hi-lo)*float64(maxHeatmapIntensity-1)))
+	return max(1, min(maxHeatmapIntensity, intensity))
+}
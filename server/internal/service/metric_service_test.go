@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+func TestValidateMetric(t *testing.T) {
+	tests := []struct {
+		name    string
+		metric  model.Metric
+		wantErr string
+	}{
+		{name: "number", metric: model.Metric{Name: "歩数", Type: model.MetricTypeNumber, Unit: "歩", Min: floatPtr(0)}},
+		{name: "blank name", metric: model.Metric{Type: model.MetricTypeNumber}, wantErr: "name must not be blank"},
+		{name: "min greater than max", metric: model.Metric{Name: "体重", Type: model.MetricTypeNumber, Min: floatPtr(100), Max: floatPtr(30)}, wantErr: "min must be less than or equal to max"},
+		{name: "boolean", metric: model.Metric{Name: "運動", Type: model.MetricTypeBoolean}},
+		{name: "boolean with unit", metric: model.Metric{Name: "運動", Type: model.MetricTypeBoolean, Unit: "回"}, wantErr: "boolean metrics cannot have unit, min or max"},
+		{name: "boolean with max", metric: model.Metric{Name: "運動", Type: model.MetricTypeBoolean, Max: floatPtr(1)}, wantErr: "boolean metrics cannot have unit, min or max"},
+		{name: "scale", metric: model.Metric{Name: "気分", Type: model.MetricTypeScale, Min: floatPtr(1), Max: floatPtr(5)}},
+		{name: "scale without max", metric: model.Metric{Name: "気分", Type: model.MetricTypeScale, Min: floatPtr(1)}, wantErr: "scale metrics require integer min and max with min < max"},
+		{name: "scale with fractions", metric: model.Metric{Name: "気分", Type: model.MetricTypeScale, Min: floatPtr(1), Max: floatPtr(4.5)}, wantErr: "scale metrics require integer min and max with min < max"},
+		{name: "scale with one step", metric: model.Metric{Name: "気分", Type: model.MetricTypeScale, Min: floatPtr(3), Max: floatPtr(3)}, wantErr: "scale metrics require integer min and max with min < max"},
+		{name: "duration", metric: model.Metric{Name: "読書", Type: model.MetricTypeDuration, Unit: "分"}},
+		{name: "negative duration", metric: model.Metric{Name: "読書", Type: model.MetricTypeDuration, Min: floatPtr(-10)}, wantErr: "min of duration metrics must be 0 or greater"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMetric(&tt.metric)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrValidation) || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	number := model.Metric{Type: model.MetricTypeNumber, Min: floatPtr(0), Max: floatPtr(10.5)}
+	boolean := model.Metric{Type: model.MetricTypeBoolean}
+	scale := model.Metric{Type: model.MetricTypeScale, Min: floatPtr(1), Max: floatPtr(5)}
+	duration := model.Metric{Type: model.MetricTypeDuration, Max: floatPtr(600)}
+
+	tests := []struct {
+		name    string
+		metric  model.Metric
+		raw     interface{}
+		want    float64
+		wantMsg string
+	}{
+		{name: "number", metric: number, raw: 2.5, want: 2.5},
+		{name: "number at max", metric: number, raw: 10.5, want: 10.5},
+		{name: "number as string", metric: number, raw: "2.5", wantMsg: "must be a number"},
+		{name: "number below min", metric: number, raw: -0.5, wantMsg: "must be 0 or greater"},
+		{name: "number above max", metric: number, raw: 11.0, wantMsg: "must be 10.5 or less"},
+		{name: "true", metric: boolean, raw: true, want: 1},
+		{name: "false", metric: boolean, raw: false, want: 0},
+		{name: "boolean as number", metric: boolean, raw: 1.0, wantMsg: "must be true or false"},
+		{name: "scale", metric: scale, raw: 3.0, want: 3},
+		{name: "scale fraction", metric: scale, raw: 2.5, wantMsg: "must be an integer"},
+		{name: "scale below min", metric: scale, raw: 0.0, wantMsg: "must be 1 or greater"},
+		{name: "scale above max", metric: scale, raw: 6.0, wantMsg: "must be 5 or less"},
+		{name: "duration", metric: duration, raw: 45.0, want: 45},
+		{name: "duration fraction", metric: duration, raw: 1.5, wantMsg: "must be an integer"},
+		{name: "negative duration", metric: duration, raw: -5.0, wantMsg: "must be 0 or greater"},
+		{name: "duration above max", metric: duration, raw: 601.0, wantMsg: "must be 600 or less"},
+		{name: "duration as bool", metric: duration, raw: true, wantMsg: "must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, msg := metricValue(tt.metric, tt.raw)
+			if msg != tt.wantMsg {
+				t.Fatalf("message = %q, want %q", msg, tt.wantMsg)
+			}
+			if got != tt.want {
+				t.Errorf("value = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiaryMetricValues(t *testing.T) {
+	store := repository.NewMemoryStore()
+	s := diaryServiceFor(store)
+	metrics := NewMetricService(store.Metrics)
+	ids := map[string]string{}
+	for _, req := range []model.CreateMetricRequest{
+		{Name: "steps", Type: model.MetricTypeNumber, Min: floatPtr(0)},
+		{Name: "exercise", Type: model.MetricTypeBoolean},
+		{Name: "mood", Type: model.MetricTypeScale, Min: floatPtr(1), Max: floatPtr(5)},
+	} {
+		m, err := metrics.Create(testUserID, req)
+		if err != nil {
+			t.Fatalf("create metric %s: %v", req.Name, err)
+		}
+		ids[req.Name] = m.ID
+	}
+	diary := func(values map[string]interface{}) model.CreateDiaryRequest {
+		return model.CreateDiaryRequest{Date: "2026-01-01", Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Metrics: values}
+	}
+
+	invalid := []struct {
+		name    string
+		values  map[string]interface{}
+		wantErr string
+	}{
+		{name: "unknown metric", values: map[string]interface{}{"unknown": 1.0}, wantErr: "metrics.unknown is not a defined metric"},
+		{name: "out of range", values: map[string]interface{}{ids["mood"]: 6.0}, wantErr: fmt.Sprintf("metrics.%s (mood) must be 5 or less", ids["mood"])},
+		{name: "wrong type", values: map[string]interface{}{ids["exercise"]: "yes"}, wantErr: fmt.Sprintf("metrics.%s (exercise) must be true or false", ids["exercise"])},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Create(testUserID, "UTC", diary(tt.values))
+			if !errors.Is(err, ErrValidation) || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	created, err := s.Create(testUserID, "UTC", diary(map[string]interface{}{ids["steps"]: 8000.0, ids["exercise"]: true, ids["mood"]: 4.0}))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	want := map[string]interface{}{ids["steps"]: 8000.0, ids["exercise"]: true, ids["mood"]: 4.0}
+	if fmt.Sprint(created.Metrics) != fmt.Sprint(want) {
+		t.Errorf("metrics = %v, want %v", created.Metrics, want)
+	}
+
+	// null を指定した項目だけが消え、false は値として残る
+	if _, err := s.Update(testUserID, "2026-01-01", model.UpdateDiaryRequest{Metrics: map[string]interface{}{ids["steps"]: nil, ids["exercise"]: false}}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := s.GetByDate(testUserID, "2026-01-01")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	want = map[string]interface{}{ids["exercise"]: false, ids["mood"]: 4.0}
+	if fmt.Sprint(got.Metrics) != fmt.Sprint(want) {
+		t.Errorf("metrics after update = %v, want %v", got.Metrics, want)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+// patternGroup は PatternGroup を作るための集計途中の値
+type patternGroup struct {
+	key       string
+	diaries   int
+	ratingSum float64
+	progress  map[string]int
+	wakeUps   []string
+	sleeps    []string
+}
+
+func newPatternGroup(key string) *patternGroup {
+	return &patternGroup{key: key, progress: map[string]int{"A": 0, "B": 0, "C": 0}}
+}
+
+func (g *patternGroup) add(d model.Diary) {
+	g.diaries++
+	g.ratingSum += float64(d.Rating)
+	g.progress[d.Progress]++
+	g.wakeUps = append(g.wakeUps, d.WakeUpTime)
+	g.sleeps = append(g.sleeps, d.SleepTime)
+}
+
+func (g *patternGroup) result() model.PatternGroup {
+	r := model.PatternGroup{
+		Key:                  g.key,
+		TotalEntries:         g.diaries,
+		ProgressDistribution: g.progress,
+	}
+	if g.diaries > 0 {
+		r.AverageRating = average(g.ratingSum, g.diaries)
+	}
+	r.AverageWakeUpTime, _, _ = clockStats(g.wakeUps)
+	r.AverageSleepTime, _, _ = clockStats(g.sleeps)
+	return r
+}
+
+// GetPatterns は期間内の日記を曜日別・月別・平日/週末別に集計する。
+// 期間の指定方法は GetStatistics と同じ。
+func (s *DiaryService) GetPatterns(userID, tz, period, startDate, endDate string) (*model.PatternAnalysis, error) {
+	startDate, endDate, period, err := s.statisticsPeriod(userID, tz, period, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	diaries, err := s.repo.GetRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 月曜始まり
+	weekdays := make([]*patternGroup, 7)
+	for i := range weekdays {
+		weekdays[i] = newPatternGroup(strings.ToLower(time.Weekday((i + 1) % 7).String()))
+	}
+	months := make([]*patternGroup, 12)
+	for i := range months {
+		months[i] = newPatternGroup(strconv.Itoa(i + 1))
+	}
+	weekday, weekend := newPatternGroup("weekday"), newPatternGroup("weekend")
+
+	for _, d := range diaries {
+		t, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		weekdays[(int(t.Weekday())+6)%7].add(d)
+		months[t.Month()-1].add(d)
+		if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+			weekend.add(d)
+		} else {
+			weekday.add(d)
+		}
+	}
+
+	analysis := &model.PatternAnalysis{
+		Period:           period,
+		PeriodStart:      startDate,
+		PeriodEnd:        endDate,
+		ByWeekday:        make([]model.PatternGroup, len(weekdays)),
+		ByMonth:          make([]model.PatternGroup, len(months)),
+		WeekdayVsWeekend: []model.PatternGroup{weekday.result(), weekend.result()},
+	}
+	for i, g := range weekdays {
+		analysis.ByWeekday[i] = g.result()
+	}
+	for i, g := range months {
+		analysis.ByMonth[i] = g.result()
+	}
+
+	return analysis, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+const (
+	// topDaysLimit は年間レポートで返す評価の高い日の件数
+	topDaysLimit = 5
+	// memoExcerptLength はメモの抜粋の最大文字数
+	memoExcerptLength = 100
+)
+
+// GetYearReport は year 年の振り返り（月ごとの評価、最長連続記録、ヒートマップ、睡眠、評価の高い日）を返す
+func (s *DiaryService) GetYearReport(userID, tz string, year int) (*model.YearReport, error) {
+	loc, err := s.location(userID, tz)
+	if err != nil {
+		return nil, err
+	}
+	if thisYear := today(loc).Year(); year < 1900 || year > thisYear {
+		return nil, validation(fmt.Sprintf("year must be between 1900 and %d", thisYear))
+	}
+	startDate := fmt.Sprintf("%04d-01-01", year)
+	endDate := fmt.Sprintf("%04d-12-31", year)
+
+	summary, err := s.summarize(userID, "year", startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	heatmap, err := s.repo.GetCalendarEntries(userID, repository.DiaryFilter{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		return nil, err
+	}
+	if heatmap == nil {
+		heatmap = []model.CalendarEntry{}
+	}
+
+	// 1月1日の睡眠時間を求めるため前日分から取得する
+	diaries, err := s.repo.GetRange(userID, addDays(startDate, -1), endDate)
+	if err != nil {
+		return nil, err
+	}
+	byDate := indexByDate(diaries)
+	var inYear []model.Diary
+	for _, d := range diaries {
+		if d.Date >= startDate {
+			inYear = append(inYear, d)
+		}
+	}
+
+	report := &model.YearReport{
+		Year:        year,
+		PeriodStart: startDate,
+		PeriodEnd:   endDate,
+		Summary:     summary,
+		Heatmap:     heatmap,
+		Months:      make([]model.ReportMonth, 12),
+		TopDays:     topDays(inYear, topDaysLimit),
+		Sleep: model.ReportSleep{
+			AverageWakeUpTime: summary.AverageWakeUpTime,
+			AverageSleepTime:  summary.AverageSleepTime,
+		},
+	}
+
+	// 月ごとの評価と睡眠時間
+	months := make([]trendAccumulator, 12)
+	var yearSleep trendAccumulator
+	for _, d := range inYear {
+		sleep, hasSleep := 0, false
+		if prev, ok := byDate[addDays(d.Date, -1)]; ok {
+			sleep, hasSleep = sleepDuration(prev.SleepTime, d.WakeUpTime)
+		}
+		month, _ := time.Parse("2006-01-02", d.Date)
+		months[month.Month()-1].add(d, sleep, hasSleep)
+		yearSleep.add(d, sleep, hasSleep)
+	}
+	for i := range months {
+		e := months[i].entry("", false)
+		report.Months[i] = model.ReportMonth{
+			Month:                       i + 1,
+			TotalEntries:                e.Entries,
+			AverageRating:               e.Rating,
+			AverageSleepDurationMinutes: e.SleepDurationMinutes,
+		}
+	}
+	report.BestMonth, report.WorstMonth = bestAndWorstMonths(report.Months)
+
+	report.Sleep.RecordedNights = yearSleep.sleepNights
+	if yearSleep.sleepNights > 0 {
+		report.Sleep.AverageDurationMinutes = average(yearSleep.sleepSum, yearSleep.sleepNights)
+	}
+	for _, m := range report.Months {
+		if m.AverageSleepDurationMinutes == nil {
+			continue
+		}
+		if report.Sleep.ShortestMonth == nil || *m.AverageSleepDurationMinutes < *report.Months[*report.Sleep.ShortestMonth-1].AverageSleepDurationMinutes {
+			report.Sleep.ShortestMonth = &m.Month
+		}
+		if report.Sleep.LongestMonth == nil || *m.AverageSleepDurationMinutes > *report.Months[*report.Sleep.LongestMonth-1].AverageSleepDurationMinutes {
+			report.Sleep.LongestMonth = &m.Month
+		}
+	}
+
+	// 年内の最長連続記録（同じ長さなら早い方）
+	dates := make([]string, len(inYear))
+	for i, d := range inYear {
+		dates[i] = d.Date
+	}
+	freezes, err := s.freezes.List(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, streak := range buildStreaks(dates, freezes) {
+		if report.LongestStreak == nil || streak.Length > report.LongestStreak.Length {
+			report.LongestStreak = &streak
+		}
+	}
+
+	// 最も多い進捗（同数なら良い方）
+	for _, p := range []string{"A", "B", "C"} {
+		if n := summary.ProgressDistribution[p]; n > 0 && (report.MostCommonProgress == "" || n > summary.ProgressDistribution[report.MostCommonProgress]) {
+			report.MostCommonProgress = p
+		}
+	}
+
+	return report, nil
+}
+
+// bestAndWorstMonths は平均評価が最も高い月と低い月を返す（同じ評価なら記録の多い月、さらに同じなら早い月）
+func bestAndWorstMonths(months []model.ReportMonth) (*model.ReportMonth, *model.ReportMonth) {
+	var best, worst *model.ReportMonth
+	for i := range months {
+		m := &months[i]
+		if m.AverageRating == nil {
+			continue
+		}
+		if best == nil || *m.AverageRating > *best.AverageRating ||
+			(*m.AverageRating == *best.AverageRating && m.TotalEntries > best.TotalEntries) {
+			best = m
+		}
+		if worst == nil || *m.AverageRating < *worst.AverageRating ||
+			(*m.AverageRating == *worst.AverageRating && m.TotalEntries > worst.TotalEntries) {
+			worst = m
+		}
+	}
+	return best, worst
+}
+
+// topDays は評価の高い日を最大 limit 件返す。同じ評価ならメモのある日、さらに同じなら新しい日を優先する。
+func topDays(diaries []model.Diary, limit int) []model.ReportDay {
+	sorted := make([]model.Diary, len(diaries))
+	copy(sorted, diaries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		if hasA, hasB := strings.TrimSpace(a.Memo) != "", strings.TrimSpace(b.Memo) != ""; hasA != hasB {
+			return hasA
+		}
+		return a.Date > b.Date
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	days := make([]model.ReportDay, len(sorted))
+	for i, d := range sorted {
+		days[i] = model.ReportDay{
+			Date:        d.Date,
+			Rating:      d.Rating,
+			Progress:    d.Progress,
+			MemoExcerpt: excerpt(d.Memo, memoExcerptLength),
+		}
+	}
+	return days
+}
+
+// excerpt は s の先頭 n 文字を返す。改行は空白にまとめる。
+func excerpt(s string, n int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+const (
+	// defaultSearchLimit は検索結果の limit を省略した場合の件数
+	defaultSearchLimit = 20
+	// snippetLength はメモの抜粋の最大文字数
+	snippetLength = 80
+	// snippetLead は抜粋で最初に一致した位置より前に含める文字数
+	snippetLead = 20
+	// searchIndexBatch は索引を作成する際にまとめて読み込む日記の数
+	searchIndexBatch = 500
+)
+
+// indexMemo は日記のメモを検索用の索引に登録する
+func indexMemo(search repository.SearchIndexRepository, userID, diaryID, memo string) error {
+	terms, length := indexTerms(memo)
+	return search.Index(userID, diaryID, string(normalizeText(memo)), length, terms)
+}
+
+// BuildSearchIndex は索引のない日記（検索機能の追加前に作成された日記など）を索引に登録し、その件数を返す
+func (s *DiaryService) BuildSearchIndex() (int, error) {
+	indexed := 0
+	for {
+		diaries, err := s.search.Unindexed(searchIndexBatch)
+		if err != nil {
+			return indexed, err
+		}
+		if len(diaries) == 0 {
+			return indexed, nil
+		}
+		for _, d := range diaries {
+			if err := indexMemo(s.search, d.UserID, d.ID, d.Memo); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+	}
+}
+
+// Search はメモに q の語をすべて含む日記を関連の高い順（同じなら新しい順）に返す。
+// 日本語は分かち書きされないため、1文字と2文字の組で索引を引き、語がそのまま含まれるかは正規化したメモで確かめる。
+func (s *DiaryService) Search(userID string, q model.SearchDiariesQuery) (*model.SearchResult, error) {
+	query := parseSearchQuery(q.Q)
+	if len(query.terms) == 0 {
+		return nil, validation("q must contain letters, numbers or Japanese characters")
+	}
+	if q.StartDate != "" && q.EndDate != "" && q.StartDate > q.EndDate {
+		return nil, validation("start_date must be on or before end_date")
+	}
+	if q.MinRating > 0 && q.MaxRating > 0 && q.MinRating > q.MaxRating {
+		return nil, validation("min_rating must be less than or equal to max_rating")
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxDiaryLimit {
+		limit = maxDiaryLimit
+	}
+
+	search := repository.SearchQuery{
+		Terms:   query.terms,
+		Phrases: query.phrases,
+		Filter: repository.DiaryFilter{
+			StartDate: q.StartDate,
+			EndDate:   q.EndDate,
+			MinRating: q.MinRating,
+			MaxRating: q.MaxRating,
+		},
+	}
+	total, err := s.search.Count(userID, search)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.search.Search(userID, search, limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	page := make([]model.SearchHit, len(matches))
+	for i, m := range matches {
+		page[i] = model.SearchHit{
+			Diary:   m.Diary,
+			Score:   m.Score,
+			Snippet: snippet([]rune(m.Diary.Memo), query.matches(normalizeText(m.Diary.Memo))),
+		}
+	}
+
+	diaries := make([]model.Diary, len(page))
+	for i, hit := range page {
+		diaries[i] = hit.Diary
+	}
+	if err := s.withSleepDurations(userID, diaries); err != nil {
+		return nil, err
+	}
+	if err := s.withTags(diaries); err != nil {
+		return nil, err
+	}
+	if err := s.withMetrics(userID, diaries); err != nil {
+		return nil, err
+	}
+	for i := range page {
+		page[i].Diary = diaries[i]
+	}
+
+	return &model.SearchResult{
+		Query:   q.Q,
+		Results: page,
+		Pagination: model.Pagination{
+			Total:   total,
+			Limit:   limit,
+			Offset:  q.Offset,
+			HasMore: q.Offset+len(page) < total,
+		},
+	}, nil
+}
+
+// snippet は最初に一致した位置の周辺 snippetLength 文字を、一致した部分とそれ以外に分けて返す。
+// 改行は空白にする。前後を省略した場合は "…" を付ける。
+func snippet(memo []rune, matches [][2]int) []model.SnippetPart {
+	start := 0
+	if len(matches) > 0 {
+		start = max(0, matches[0][0]-snippetLead)
+	}
+	end := min(len(memo), start+snippetLength)
+	if end-start < snippetLength {
+		start = max(0, end-snippetLength)
+	}
+
+	text := func(from, to int) string {
+		runes := make([]rune, 0, to-from)
+		for _, r := range memo[from:to] {
+			if r == '\n' || r == '\r' || r == '\t' {
+				r = ' '
+			}
+			runes = append(runes, r)
+		}
+		return string(runes)
+	}
+
+	var parts []model.SnippetPart
+	appendPart := func(s string, highlight bool) {
+		if s == "" {
+			return
+		}
+		if n := len(parts); n > 0 && parts[n-1].Highlight == highlight {
+			parts[n-1].Text += s
+			return
+		}
+		parts = append(parts, model.SnippetPart{Text: s, Highlight: highlight})
+	}
+
+	if start > 0 {
+		appendPart("…", false)
+	}
+	pos := start
+	for _, m := range matches {
+		from, to := max(m[0], start), min(m[1], end)
+		if from >= to {
+			continue
+		}
+		appendPart(text(pos, from), false)
+		appendPart(text(from, to), true)
+		pos = to
+	}
+	appendPart(text(pos, end), false)
+	if end < len(memo) {
+		appendPart("…", false)
+	}
+
+	if parts == nil {
+		parts = []model.SnippetPart{}
+	}
+	return parts
+}
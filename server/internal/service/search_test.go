@@ -0,0 +1,158 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+func TestSnippet(t *testing.T) {
+	a := func(n int) string { return strings.Repeat("あ", n) }
+	b := func(n int) string { return strings.Repeat("い", n) }
+	tests := []struct {
+		name    string
+		memo    string
+		matches [][2]int
+		want    []model.SnippetPart
+	}{
+		{name: "empty", memo: "", want: []model.SnippetPart{}},
+		{name: "no match", memo: "晴れ", want: []model.SnippetPart{{Text: "晴れ"}}},
+		{
+			name:    "short memo",
+			memo:    "公園を\n散歩した",
+			matches: [][2]int{{4, 6}},
+			want:    []model.SnippetPart{{Text: "公園を "}, {Text: "散歩", Highlight: true}, {Text: "した"}},
+		},
+		{
+			name:    "match near the start",
+			memo:    a(10) + "散歩" + b(100),
+			matches: [][2]int{{10, 12}},
+			want:    []model.SnippetPart{{Text: a(10)}, {Text: "散歩", Highlight: true}, {Text: b(68) + "…"}},
+		},
+		{
+			name:    "match in the middle",
+			memo:    a(50) + "散歩" + b(100),
+			matches: [][2]int{{50, 52}},
+			want:    []model.SnippetPart{{Text: "…" + a(20)}, {Text: "散歩", Highlight: true}, {Text: b(58) + "…"}},
+		},
+		{
+			name:    "match near the end",
+			memo:    a(50) + "散歩" + b(50),
+			matches: [][2]int{{50, 52}},
+			want:    []model.SnippetPart{{Text: "…" + a(28)}, {Text: "散歩", Highlight: true}, {Text: b(50)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := snippet([]rune(tt.memo), tt.matches)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := newTestDiaryService(t)
+	createDiaries(t, s,
+		model.CreateDiaryRequest{Date: "2026-09-01", Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Memo: "公園を散歩した。散歩は楽しい"},
+		model.CreateDiaryRequest{Date: "2026-09-02", Rating: 4, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Memo: "散った歩道を歩いた"},
+		model.CreateDiaryRequest{Date: "2026-09-03", Rating: 5, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Memo: "東京と京都に行った"},
+		model.CreateDiaryRequest{Date: "2026-09-04", Rating: 2, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Memo: "東京都で会議。Go言語の勉強"},
+		model.CreateDiaryRequest{Date: "2026-09-05", Rating: 4, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Memo: "Running in the park. GO GO"},
+		model.CreateDiaryRequest{Date: "2026-09-06", Rating: 4, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Memo: "散歩"},
+	)
+
+	tests := []struct {
+		name      string
+		q         model.SearchDiariesQuery
+		want      []string
+		wantTotal int
+	}{
+		{name: "shorter memo ranks first", q: model.SearchDiariesQuery{Q: "散歩"}, want: []string{"2026-09-06", "2026-09-01"}, wantTotal: 2},
+		{name: "characters out of order", q: model.SearchDiariesQuery{Q: "東京都"}, want: []string{"2026-09-04"}, wantTotal: 1},
+		{name: "more occurrences rank first", q: model.SearchDiariesQuery{Q: "ｇｏ"}, want: []string{"2026-09-05", "2026-09-04"}, wantTotal: 2},
+		{name: "all words", q: model.SearchDiariesQuery{Q: "go 勉強"}, want: []string{"2026-09-04"}, wantTotal: 1},
+		{name: "whole words only", q: model.SearchDiariesQuery{Q: "run"}, want: nil, wantTotal: 0},
+		{name: "filter", q: model.SearchDiariesQuery{Q: "散歩", MinRating: 4}, want: []string{"2026-09-06"}, wantTotal: 1},
+		{name: "offset", q: model.SearchDiariesQuery{Q: "散歩", Limit: 1, Offset: 1}, want: []string{"2026-09-01"}, wantTotal: 2},
+		{name: "offset past the end", q: model.SearchDiariesQuery{Q: "散歩", Offset: 5}, want: nil, wantTotal: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Search(testUserID, tt.q)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, hit := range result.Results {
+				got = append(got, hit.Diary.Date)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dates = %v, want %v", got, tt.want)
+			}
+			if result.Pagination.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", result.Pagination.Total, tt.wantTotal)
+			}
+			if want := tt.q.Offset+len(got) < tt.wantTotal; result.Pagination.HasMore != want {
+				t.Errorf("has_more = %v, want %v", result.Pagination.HasMore, want)
+			}
+		})
+	}
+}
+
+func TestSearchIndexFollowsUpdates(t *testing.T) {
+	s := newTestDiaryService(t)
+	createDiaries(t, s, model.CreateDiaryRequest{Date: "2026-09-01", Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Memo: "散歩した"})
+
+	memo := "読書した"
+	if _, err := s.Update(testUserID, "2026-09-01", model.UpdateDiaryRequest{Memo: &memo}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	for q, want := range map[string]int{"散歩": 0, "読書": 1} {
+		result, err := s.Search(testUserID, model.SearchDiariesQuery{Q: q})
+		if err != nil {
+			t.Fatalf("search %s: %v", q, err)
+		}
+		if result.Pagination.Total != want {
+			t.Errorf("search %s: total = %d, want %d", q, result.Pagination.Total, want)
+		}
+	}
+
+	if err := s.Delete(testUserID, "2026-09-01"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	result, err := s.Search(testUserID, model.SearchDiariesQuery{Q: "読書"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if result.Pagination.Total != 0 {
+		t.Errorf("total after delete = %d, want 0", result.Pagination.Total)
+	}
+}
+
+func TestSearchValidation(t *testing.T) {
+	s := newTestDiaryService(t)
+	tests := []struct {
+		name    string
+		q       model.SearchDiariesQuery
+		wantErr string
+	}{
+		{name: "symbols only", q: model.SearchDiariesQuery{Q: "!?"}, wantErr: "q must contain letters, numbers or Japanese characters"},
+		{name: "dates reversed", q: model.SearchDiariesQuery{Q: "a", StartDate: "2026-02-01", EndDate: "2026-01-01"}, wantErr: "start_date must be on or before end_date"},
+		{name: "ratings reversed", q: model.SearchDiariesQuery{Q: "a", MinRating: 4, MaxRating: 2}, wantErr: "min_rating must be less than or equal to max_rating"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Search(testUserID, tt.q)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+const (
+	// sleepDebtWindowDays は睡眠負債を累積する直近の日数
+	sleepDebtWindowDays = 7
+	// shortestNightsLimit は睡眠時間が短かった夜として返す件数
+	shortestNightsLimit = 5
+)
+
+// sleepDuration は前日の就寝時刻と当日の起床時刻から睡眠時間（分）を求める。
+// 24 時間周期で差を取るため、0 時以降の就寝（例: 01:00）も扱える。
+func sleepDuration(prevSleepTime, wakeUpTime string) (int, bool) {
+	bed, ok := parseClock(prevSleepTime)
+	if !ok {
+		return 0, false
+	}
+	wake, ok := parseClock(wakeUpTime)
+	if !ok {
+		return 0, false
+	}
+
+	d := ((wake-bed)%minutesPerDay + minutesPerDay) % minutesPerDay
+	if d == 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// addDays は YYYY-MM-DD の日付を n 日ずらす
+func addDays(date string, n int) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.AddDate(0, 0, n).Format("2006-01-02")
+}
+
+// attachSleepDurations は各日記に前日の日記から求めた睡眠時間を設定する
+func attachSleepDurations(diaries []model.Diary, byDate map[string]model.Diary) {
+	for i := range diaries {
+		prev, ok := byDate[addDays(diaries[i].Date, -1)]
+		if !ok {
+			continue
+		}
+		if d, ok := sleepDuration(prev.SleepTime, diaries[i].WakeUpTime); ok {
+			diaries[i].SleepDurationMinutes = &d
+		}
+	}
+}
+
+// withSleepDurations は一覧の日記に睡眠時間を設定する。一覧外の前日分はまとめて取得する。
+func (s *DiaryService) withSleepDurations(userID string, diaries []model.Diary) error {
+	if len(diaries) == 0 {
+		return nil
+	}
+
+	first, last := diaries[0].Date, diaries[0].Date
+	for _, d := range diaries {
+		if d.Date < first {
+			first = d.Date
+		}
+		if d.Date > last {
+			last = d.Date
+		}
+	}
+
+	around, err := s.repo.GetRange(userID, addDays(first, -1), last)
+	if err != nil {
+		return err
+	}
+	attachSleepDurations(diaries, indexByDate(around))
+	return nil
+}
+
+func indexByDate(diaries []model.Diary) map[string]model.Diary {
+	byDate := make(map[string]model.Diary, len(diaries))
+	for _, d := range diaries {
+		byDate[d.Date] = d
+	}
+	return byDate
+}
+
+// GetSleepStatistics は直近 days 日の睡眠時間と目標に対する睡眠負債を集計する
+func (s *DiaryService) GetSleepStatistics(userID, tz string, days int) (*model.SleepStatistics, error) {
+	settings, err := s.users.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	target := model.DefaultSleepTargetMinutes
+	if settings != nil {
+		target = settings.SleepTargetMinutes
+	}
+
+	loc, err := s.location(userID, tz)
+	if err != nil {
+		return nil, err
+	}
+	endDate := today(loc).Format("2006-01-02")
+	startDate := addDays(endDate, -days)
+
+	// 期間初日の睡眠時間を求めるため前日分から取得する
+	diaries, err := s.repo.GetRange(userID, addDays(startDate, -1), endDate)
+	if err != nil {
+		return nil, err
+	}
+	byDate := indexByDate(diaries)
+
+	nights := []model.SleepNight{}
+	total := 0
+	for _, d := range diaries {
+		if d.Date < startDate {
+			continue
+		}
+		prev, ok := byDate[addDays(d.Date, -1)]
+		if !ok {
+			continue
+		}
+		duration, ok := sleepDuration(prev.SleepTime, d.WakeUpTime)
+		if !ok {
+			continue
+		}
+		nights = append(nights, model.SleepNight{
+			Date:            d.Date,
+			SleepTime:       prev.SleepTime,
+			WakeUpTime:      d.WakeUpTime,
+			DurationMinutes: duration,
+		})
+		total += duration
+	}
+
+	for i := range nights {
+		nights[i].DebtMinutes = sleepDebt(nights, target, nights[i].Date)
+	}
+
+	stats := &model.SleepStatistics{
+		PeriodDays:       days,
+		PeriodStart:      startDate,
+		PeriodEnd:        endDate,
+		TargetMinutes:    target,
+		RecordedNights:   len(nights),
+		DebtWindowDays:   sleepDebtWindowDays,
+		SleepDebtMinutes: sleepDebt(nights, target, endDate),
+		Nights:           nights,
+		ShortestNights:   shortestNights(nights, shortestNightsLimit),
+	}
+	if len(nights) > 0 {
+		stats.AverageDurationMinutes = math.Round(float64(total)/float64(len(nights))*10) / 10
+	}
+
+	return stats, nil
+}
+
+// sleepDebt は date までの直近 sleepDebtWindowDays 日の (目標 - 実績) の合計を返す。
+// 目標を上回った日は負債の返済として扱い、合計が負の場合は 0 とする。
+func sleepDebt(nights []model.SleepNight, target int, date string) int {
+	from := addDays(date, -(sleepDebtWindowDays - 1))
+	debt := 0
+	for _, n := range nights {
+		if n.Date < from || n.Date > date {
+			continue
+		}
+		debt += target - n.DurationMinutes
+	}
+	if debt < 0 {
+		return 0
+	}
+	return debt
+}
+
+// shortestNights は睡眠時間の短い順に最大 limit 件返す（同じ長さなら新しい日付を優先）
+func shortestNights(nights []model.SleepNight, limit int) []model.SleepNight {
+	sorted := make([]model.SleepNight, len(nights))
+	copy(sorted, nights)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].DurationMinutes != sorted[j].DurationMinutes {
+			return sorted[i].DurationMinutes < sorted[j].DurationMinutes
+		}
+		return sorted[i].Date > sorted[j].Date
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+// freezesPerMonth は1か月に使えるフリーズの数
+const freezesPerMonth = 2
+
+// buildStreaks は記録日とフリーズ日から連続記録の一覧を古い順に返す。
+// フリーズ日は連続を途切れさせないが記録日数には数えない。記録のない連続（フリーズのみ）は含めない。
+func buildStreaks(recorded, freezes []string) []model.Streak {
+	isRecorded := make(map[string]bool, len(recorded))
+	covered := make(map[string]bool, len(recorded)+len(freezes))
+	for _, d := range recorded {
+		isRecorded[d] = true
+		covered[d] = true
+	}
+	for _, d := range freezes {
+		covered[d] = true
+	}
+
+	dates := make([]string, 0, len(covered))
+	for d := range covered {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	var streaks []model.Streak
+	var current *model.Streak
+	prev := ""
+	for _, d := range dates {
+		if current != nil && addDays(prev, 1) != d {
+			streaks = append(streaks, *current)
+			current = nil
+		}
+		prev = d
+
+		if current == nil {
+			// フリーズ日から始まる連続は最初の記録日から数える
+			if !isRecorded[d] {
+				continue
+			}
+			current = &model.Streak{StartDate: d}
+		}
+		current.EndDate = d
+		if isRecorded[d] {
+			current.Length++
+		} else {
+			current.FrozenDays++
+		}
+	}
+	if current != nil {
+		streaks = append(streaks, *current)
+	}
+
+	return streaks
+}
+
+func longestStreak(streaks []model.Streak) int {
+	longest := 0
+	for _, s := range streaks {
+		if s.Length > longest {
+			longest = s.Length
+		}
+	}
+	return longest
+}
+
+// GetStreaks は継続中の連続記録・これまでの連続記録・フリーズの残りを返す
+func (s *DiaryService) GetStreaks(userID, tz string) (*model.StreakStatus, error) {
+	loc, err := s.location(userID, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := s.repo.GetDates(userID)
+	if err != nil {
+		return nil, err
+	}
+	freezes, err := s.freezes.List(userID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	today := today(loc).Format("2006-01-02")
+	streaks := buildStreaks(recorded, freezes)
+
+	status := &model.StreakStatus{
+		Today:           today,
+		LongestStreak:   longestStreak(streaks),
+		FreezesPerMonth: freezesPerMonth,
+		Freezes:         []string{},
+		History:         make([]model.Streak, 0, len(streaks)),
+	}
+	if freezes != nil {
+		status.Freezes = freezes
+	}
+	for i := len(streaks) - 1; i >= 0; i-- {
+		status.History = append(status.History, streaks[i])
+	}
+
+	for _, d := range recorded {
+		if d == today {
+			status.RecordedToday = true
+		}
+	}
+
+	// 最後の連続が今日または昨日まで続いていれば継続中
+	if len(streaks) > 0 {
+		last := streaks[len(streaks)-1]
+		if last.EndDate >= addDays(today, -1) {
+			status.Current = &last
+			status.CurrentStreak = last.Length
+			status.AtRisk = last.EndDate < today
+		}
+	}
+
+	used := 0
+	for _, d := range freezes {
+		if d[:7] == today[:7] {
+			used++
+		}
+	}
+	status.FreezesAvailable = max(freezesPerMonth-used, 0)
+
+	return status, nil
+}
+
+// CreateStreakFreeze は記録のない日にフリーズを使う。フリーズは月ごとに freezesPerMonth 回まで。
+func (s *DiaryService) CreateStreakFreeze(userID, tz, date string) error {
+	loc, err := s.location(userID, tz)
+	if err != nil {
+		return err
+	}
+	if date > today(loc).Format("2006-01-02") {
+		return validation("Cannot freeze a future date")
+	}
+
+	diary, err := s.repo.GetByDate(userID, date)
+	if err != nil {
+		return err
+	}
+	if diary != nil {
+		return validation("A diary already exists for this date")
+	}
+
+	day, _ := time.Parse("2006-01-02", date)
+	firstDay := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1)
+	used, err := s.freezes.List(userID, firstDay.Format("2006-01-02"), lastDay.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	if len(used) >= freezesPerMonth {
+		return conflict("FREEZE_LIMIT_REACHED", "No streak freezes left for this month")
+	}
+
+	if err := s.freezes.Create(userID, date, time.Now().UTC()); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return conflict("DUPLICATE_ENTRY", "A freeze is already used for this date")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteStreakFreeze は使ったフリーズを取り消す
+func (s *DiaryService) DeleteStreakFreeze(userID, date string) error {
+	deleted, err := s.freezes.Delete(userID, date)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return notFound("Streak freeze not found")
+	}
+	return nil
+}
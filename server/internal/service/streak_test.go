@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+func TestBuildStreaks(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorded []string
+		freezes  []string
+		want     []model.Streak
+	}{
+		{name: "no records", want: nil},
+		{name: "single day", recorded: []string{"2026-01-01"}, want: []model.Streak{{StartDate: "2026-01-01", EndDate: "2026-01-01", Length: 1}}},
+		{
+			name:     "gap splits streaks",
+			recorded: []string{"2026-01-05", "2026-01-01", "2026-01-02", "2026-01-04"},
+			want: []model.Streak{
+				{StartDate: "2026-01-01", EndDate: "2026-01-02", Length: 2},
+				{StartDate: "2026-01-04", EndDate: "2026-01-05", Length: 2},
+			},
+		},
+		{
+			name:     "across month and year",
+			recorded: []string{"2025-12-31", "2026-01-01", "2026-02-28", "2026-03-01"},
+			want: []model.Streak{
+				{StartDate: "2025-12-31", EndDate: "2026-01-01", Length: 2},
+				{StartDate: "2026-02-28", EndDate: "2026-03-01", Length: 2},
+			},
+		},
+		{
+			name:     "freeze bridges a gap",
+			recorded: []string{"2026-01-01", "2026-01-03"},
+			freezes:  []string{"2026-01-02"},
+			want:     []model.Streak{{StartDate: "2026-01-01", EndDate: "2026-01-03", Length: 2, FrozenDays: 1}},
+		},
+		{
+			name:     "leading freeze is not counted",
+			recorded: []string{"2026-01-02"},
+			freezes:  []string{"2026-01-01"},
+			want:     []model.Streak{{StartDate: "2026-01-02", EndDate: "2026-01-02", Length: 1}},
+		},
+		{
+			name:     "trailing freeze extends the end",
+			recorded: []string{"2026-01-01"},
+			freezes:  []string{"2026-01-02"},
+			want:     []model.Streak{{StartDate: "2026-01-01", EndDate: "2026-01-02", Length: 1, FrozenDays: 1}},
+		},
+		{name: "freezes only", freezes: []string{"2026-01-01", "2026-01-02"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildStreaks(tt.recorded, tt.freezes)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStreaks(t *testing.T) {
+	s := newTestDiaryService(t)
+	today := today(time.UTC).Format("2006-01-02")
+	day := func(offset int) string { return addDays(today, offset) }
+	diary := func(date string) model.CreateDiaryRequest {
+		return model.CreateDiaryRequest{Date: date, Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00"}
+	}
+
+	createDiaries(t, s, diary(day(-12)), diary(day(-11)), diary(day(-10)), diary(day(-4)), diary(day(-2)), diary(day(-1)))
+	if err := s.CreateStreakFreeze(testUserID, "UTC", day(-3)); err != nil {
+		t.Fatalf("freeze: %v", err)
+	}
+
+	status, err := s.GetStreaks(testUserID, "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantCurrent := model.Streak{StartDate: day(-4), EndDate: day(-1), Length: 3, FrozenDays: 1}
+	if status.Current == nil || *status.Current != wantCurrent {
+		t.Errorf("current = %+v, want %+v", status.Current, wantCurrent)
+	}
+	if status.CurrentStreak != 3 || status.LongestStreak != 3 {
+		t.Errorf("current streak = %d, longest = %d, want 3 and 3", status.CurrentStreak, status.LongestStreak)
+	}
+	if !status.AtRisk || status.RecordedToday {
+		t.Errorf("at risk = %v, recorded today = %v, want true and false", status.AtRisk, status.RecordedToday)
+	}
+	if len(status.History) != 2 || status.History[0] != wantCurrent || status.History[1].StartDate != day(-12) {
+		t.Errorf("history = %+v", status.History)
+	}
+	wantAvailable := freezesPerMonth
+	if day(-3)[:7] == today[:7] {
+		wantAvailable--
+	}
+	if status.FreezesAvailable != wantAvailable {
+		t.Errorf("freezes available = %d, want %d", status.FreezesAvailable, wantAvailable)
+	}
+
+	// 今日記録すると危険ではなくなる
+	createDiaries(t, s, diary(today))
+	status, err = s.GetStreaks(testUserID, "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.CurrentStreak != 4 || status.AtRisk || !status.RecordedToday {
+		t.Errorf("current streak = %d, at risk = %v, recorded today = %v", status.CurrentStreak, status.AtRisk, status.RecordedToday)
+	}
+}
+
+func TestGetStreaksBroken(t *testing.T) {
+	s := newTestDiaryService(t)
+	today := today(time.UTC).Format("2006-01-02")
+	createDiaries(t, s, model.CreateDiaryRequest{Date: addDays(today, -2), Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00"})
+
+	status, err := s.GetStreaks(testUserID, "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Current != nil || status.CurrentStreak != 0 || status.LongestStreak != 1 {
+		t.Errorf("current = %+v, current streak = %d, longest = %d", status.Current, status.CurrentStreak, status.LongestStreak)
+	}
+}
+
+func TestCreateStreakFreeze(t *testing.T) {
+	s := newTestDiaryService(t)
+	createDiaries(t, s, model.CreateDiaryRequest{Date: "2026-01-05", Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00"})
+	future := addDays(today(time.UTC).Format("2006-01-02"), 2)
+
+	tests := []struct {
+		name     string
+		date     string
+		wantKind error
+		wantErr  string
+	}{
+		{name: "first", date: "2026-01-01"},
+		{name: "duplicate", date: "2026-01-01", wantKind: ErrConflict, wantErr: "A freeze is already used for this date"},
+		{name: "recorded day", date: "2026-01-05", wantKind: ErrValidation, wantErr: "A diary already exists for this date"},
+		{name: "future", date: future, wantKind: ErrValidation, wantErr: "Cannot freeze a future date"},
+		{name: "second", date: "2026-01-02"},
+		{name: "over the monthly limit", date: "2026-01-03", wantKind: ErrConflict, wantErr: "No streak freezes left for this month"},
+		{name: "next month", date: "2026-02-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.CreateStreakFreeze(testUserID, "UTC", tt.date)
+			if tt.wantKind == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantKind) || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+var errTagNotFound = notFound("Tag not found")
+
+type TagService struct {
+	tags repository.TagRepository
+}
+
+func NewTagService(tags repository.TagRepository) *TagService {
+	return &TagService{tags: tags}
+}
+
+func (s *TagService) List(userID string) ([]model.Tag, error) {
+	tags, err := s.tags.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []model.Tag{}
+	}
+	return tags, nil
+}
+
+// get は該当するタグがない場合 ErrNotFound を返す。
+// UUID でない ID は PostgreSQL で型エラーになるため、問い合わせずに見つからない扱いにする。
+func (s *TagService) get(userID, id string) (*model.Tag, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errTagNotFound
+	}
+	tag, err := s.tags.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, errTagNotFound
+	}
+	return tag, nil
+}
+
+// Rename は同じ名前のタグがある場合 ErrConflict を返す
+func (s *TagService) Rename(userID, id string, req model.RenameTagRequest) (*model.Tag, error) {
+	if _, err := s.get(userID, id); err != nil {
+		return nil, err
+	}
+
+	renamed, err := s.tags.Rename(userID, id, normalizeTagName(req.Name))
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, conflict("TAG_ALREADY_EXISTS", "Tag with this name already exists")
+		}
+		return nil, err
+	}
+	if !renamed {
+		return nil, errTagNotFound
+	}
+
+	return s.get(userID, id)
+}
+
+// Merge は id のタグを req.TargetID のタグに統合し、統合後のタグを返す
+func (s *TagService) Merge(userID, id string, req model.MergeTagRequest) (*model.Tag, error) {
+	if id == req.TargetID {
+		return nil, validation("target_id must be different from the tag to merge")
+	}
+	if _, err := s.get(userID, id); err != nil {
+		return nil, err
+	}
+	if _, err := s.get(userID, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	if err := s.tags.Merge(userID, id, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	return s.get(userID, req.TargetID)
+}
+
+// Delete はタグを削除する（日記からも外れる）
+func (s *TagService) Delete(userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return errTagNotFound
+	}
+	deleted, err := s.tags.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errTagNotFound
+	}
+	return nil
+}
+
+// normalizeTagName は前後の空白を除き、小文字にそろえる。
+// タグ名は大文字と小文字を区別しないため、バックエンドの照合順序によらず同じ名前として扱う
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags は名前をそろえ、空の名前と重複を取り除く（順序は保つ）
+func normalizeTags(names []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// tagFilter はカンマ区切りのタグ名と tag_match（all / any、空の場合は all）を絞り込み条件にする
+func tagFilter(tags, match string) ([]string, bool, error) {
+	if match != "" && match != "all" && match != "any" {
+		return nil, false, validation("tag_match must be one of all, any")
+	}
+	names := normalizeTags(strings.Split(tags, ","))
+	if len(names) == 0 {
+		names = nil
+	}
+	return names, match == "any", nil
+}
+
+// withTags は日記にタグ名を設定する（タグがない場合は空配列）
+func (s *DiaryService) withTags(diaries []model.Diary) error {
+	ids := make([]string, len(diaries))
+	for i, d := range diaries {
+		ids[i] = d.ID
+	}
+	tags, err := s.tags.ListForDiaries(ids)
+	if err != nil {
+		return err
+	}
+	for i := range diaries {
+		diaries[i].Tags = tags[diaries[i].ID]
+		if diaries[i].Tags == nil {
+			diaries[i].Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// tagStats は期間内のタグごとの集計を返す（平均評価は小数第2位まで）
+func (s *DiaryService) tagStats(userID, startDate, endDate string) ([]model.TagStat, error) {
+	stats, err := s.tags.Stats(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = []model.TagStat{}
+	}
+	for i := range stats {
+		stats[i].AverageRating = math.Round(stats[i].AverageRating*100) / 100
+	}
+	return stats, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{name: "empty", names: nil, want: []string{}},
+		{name: "trimmed", names: []string{" work ", "gym"}, want: []string{"work", "gym"}},
+		{name: "blank names are dropped", names: []string{"", "  ", "work"}, want: []string{"work"}},
+		{name: "case is ignored", names: []string{"Work", "WORK", "work"}, want: []string{"work"}},
+		{name: "order is kept", names: []string{"Gym", "work", "gym"}, want: []string{"gym", "work"}},
+		{name: "full-width letters", names: []string{"ＧＹＭ", "ｇｙｍ"}, want: []string{"ｇｙｍ"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeTags(tt.names); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		tags, match  string
+		want         []string
+		wantMatchAny bool
+		wantErr      bool
+	}{
+		{name: "none", tags: "", want: nil},
+		{name: "all by default", tags: "Work, gym", want: []string{"work", "gym"}},
+		{name: "any", tags: "work,GYM,Work", match: "any", want: []string{"work", "gym"}, wantMatchAny: true},
+		{name: "only commas", tags: ",,", want: nil},
+		{name: "invalid match", tags: "work", match: "some", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matchAny, err := tagFilter(tt.tags, tt.match)
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Errorf("error = %v, want ErrValidation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) || matchAny != tt.wantMatchAny {
+				t.Errorf("got %q, %v, want %q, %v", got, matchAny, tt.want, tt.wantMatchAny)
+			}
+		})
+	}
+}
+
+func TestTagsIgnoreCase(t *testing.T) {
+	store := repository.NewMemoryStore()
+	s := diaryServiceFor(store)
+	tags := NewTagService(store.Tags)
+	createDiaries(t, s,
+		model.CreateDiaryRequest{Date: "2026-01-01", Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Tags: []string{"Work", " WORK "}},
+		model.CreateDiaryRequest{Date: "2026-01-02", Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00", Tags: []string{"work", "Gym"}},
+		model.CreateDiaryRequest{Date: "2026-01-03", Rating: 3, Progress: "A", WakeUpTime: "07:00", SleepTime: "23:00"},
+	)
+
+	list, err := tags.List(testUserID)
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	counts := map[string]int{}
+	ids := map[string]string{}
+	for _, tag := range list {
+		counts[tag.Name] = tag.DiaryCount
+		ids[tag.Name] = tag.ID
+	}
+	if want := map[string]int{"gym": 1, "work": 2}; !maps.Equal(counts, want) {
+		t.Errorf("diary counts = %v, want %v", counts, want)
+	}
+
+	filters := []struct {
+		name string
+		q    model.ListDiariesQuery
+		want []string
+	}{
+		{name: "tags", q: model.ListDiariesQuery{Tags: "WORK,gym"}, want: []string{"2026-01-02"}},
+		{name: "any tag", q: model.ListDiariesQuery{Tags: "Gym,Work", TagMatch: "any"}, want: []string{"2026-01-02", "2026-01-01"}},
+		{name: "tag expression", q: model.ListDiariesQuery{Filter: "tag:Work"}, want: []string{"2026-01-02", "2026-01-01"}},
+		{name: "without tag", q: model.ListDiariesQuery{Filter: `tag!="GYM"`}, want: []string{"2026-01-03", "2026-01-01"}},
+	}
+	for _, tt := range filters {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.GetAll(testUserID, tt.q)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, d := range result.Diaries {
+				got = append(got, d.Date)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 大文字と小文字だけが違う名前には変更できない
+	if _, err := tags.Rename(testUserID, ids["gym"], model.RenameTagRequest{Name: "WORK"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("rename to WORK: error = %v, want ErrConflict", err)
+	}
+	renamed, err := tags.Rename(testUserID, ids["gym"], model.RenameTagRequest{Name: " Fitness "})
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if renamed.Name != "fitness" {
+		t.Errorf("renamed = %q, want fitness", renamed.Name)
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"time"
+)
+
+// location は日付の区切りに使うタイムゾーンを返す。
+// override（X-Timezone ヘッダー）、ユーザー設定、サーバーのタイムゾーンの順に優先する。
+func (s *DiaryService) location(userID, override string) (*time.Location, error) {
+	if override != "" {
+		loc, err := loadLocation(override)
+		if err != nil {
+			return nil, validation("X-Timezone must be an IANA time zone name")
+		}
+		return loc, nil
+	}
+
+	settings, err := s.users.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil || settings.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := loadLocation(settings.Timezone)
+	if err != nil {
+		// 保存時に検証しているため通常は起きない
+		return time.Local, nil
+	}
+	return loc, nil
+}
+
+// loadLocation は IANA タイムゾーン名を読み込む。"Local" はサーバー依存になるため受け付けない。
+func loadLocation(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, errors.New("unknown time zone Local")
+	}
+	return time.LoadLocation(name)
+}
+
+// today は loc での今日の日付を返す（時刻は 0:00 UTC）。日付の計算は UTC の暦として行う。
+func today(loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxTermLength は英数字の語を索引に登録する最大文字数（超える部分は切り捨てる）
+const maxTermLength = 32
+
+// normalizeText は全角英数字・半角カナなどを NFKC で揃え、小文字にする。
+// 元の文字列と位置を対応させるため、1文字が1文字に変換される場合のみ置き換える。
+func normalizeText(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		if n := []rune(norm.NFKC.String(string(r))); len(n) == 1 {
+			r = n[0]
+		}
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// isJapanese は分かち書きされない文字（漢字・ひらがな・カタカナ）かどうか
+func isJapanese(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー' || r == '々'
+}
+
+// textSegment は英数字の単語、または日本語の文字の連続
+type textSegment struct {
+	text     []rune
+	start    int // 正規化した文字列での位置（文字単位）
+	japanese bool
+}
+
+// segmentText は正規化した文字列を単語と日本語の連続に分ける。記号や空白は区切りとして捨てる。
+func segmentText(text []rune) []textSegment {
+	var segments []textSegment
+	var current *textSegment
+	for i, r := range text {
+		japanese := isJapanese(r)
+		if !japanese && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			current = nil
+			continue
+		}
+		if current == nil || current.japanese != japanese {
+			segments = append(segments, textSegment{start: i, japanese: japanese})
+			current = &segments[len(segments)-1]
+		}
+		current.text = append(current.text, r)
+	}
+	return segments
+}
+
+// segmentTerms は索引語を返す。単語はそのまま、日本語は1文字ずつと隣り合う2文字の組にする。
+// query が true の場合は検索語として、日本語は2文字の組（1文字だけの場合はその文字）のみ返す。
+func segmentTerms(seg textSegment, query bool) []string {
+	if !seg.japanese {
+		text := seg.text
+		if len(text) > maxTermLength {
+			text = text[:maxTermLength]
+		}
+		return []string{string(text)}
+	}
+
+	var terms []string
+	if !query || len(seg.text) == 1 {
+		for _, r := range seg.text {
+			terms = append(terms, string(r))
+		}
+	}
+	for i := 0; i+1 < len(seg.text); i++ {
+		terms = append(terms, string(seg.text[i:i+2]))
+	}
+	return terms
+}
+
+// indexTerms はメモの索引語ごとの出現回数と語数を返す
+func indexTerms(memo string) (map[string]int, int) {
+	terms := map[string]int{}
+	length := 0
+	for _, seg := range segmentText(normalizeText(memo)) {
+		for _, term := range segmentTerms(seg, false) {
+			terms[term]++
+			length++
+		}
+	}
+	return terms, length
+}
+
+// searchQuery は検索語を解析したもの
+type searchQuery struct {
+	// terms は日記がすべて含む必要のある索引語（重複なし）
+	terms []string
+	// phrases は正規化したメモに文字列として含まれる必要のある語（日本語の2文字の組だけでは語順を確かめられないため）
+	phrases []string
+}
+
+func parseSearchQuery(q string) searchQuery {
+	var parsed searchQuery
+	seen := map[string]bool{}
+	for _, seg := range segmentText(normalizeText(q)) {
+		parsed.phrases = append(parsed.phrases, string(seg.text))
+		for _, term := range segmentTerms(seg, true) {
+			if !seen[term] {
+				seen[term] = true
+				parsed.terms = append(parsed.terms, term)
+			}
+		}
+	}
+	return parsed
+}
+
+// matches は正規化したメモで検索語に一致する範囲 [start, end) を位置の順に返す（重なる範囲はまとめる）
+func (q searchQuery) matches(text []rune) [][2]int {
+	covered := make([]bool, len(text))
+	normalized := string(text)
+	for _, phrase := range q.phrases {
+		p := []rune(phrase)
+		for offset := 0; ; {
+			i := strings.Index(normalized[offset:], phrase)
+			if i < 0 {
+				break
+			}
+			start := len([]rune(normalized[:offset+i]))
+			for j := start; j < start+len(p); j++ {
+				covered[j] = true
+			}
+			offset += i + len(phrase)
+		}
+	}
+
+	var ranges [][2]int
+	for i := 0; i < len(covered); i++ {
+		if !covered[i] {
+			continue
+		}
+		start := i
+		for i < len(covered) && covered[i] {
+			i++
+		}
+		ranges = append(ranges, [2]int{start, i})
+	}
+	return ranges
+}
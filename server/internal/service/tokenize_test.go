@@ -0,0 +1,132 @@
+package service
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "Go", want: "go"},
+		{input: "ＧＯ１２３", want: "go123"},
+		{input: "ｶﾀｶﾅ", want: "カタカナ"},
+		{input: "散歩", want: "散歩"},
+		// 1文字が複数の文字になる変換（㌔ -> キロ）はしない
+		{input: "㌔", want: "㌔"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := string(normalizeText(tt.input))
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexTerms(t *testing.T) {
+	tests := []struct {
+		name       string
+		memo       string
+		wantTerms  map[string]int
+		wantLength int
+	}{
+		{name: "empty", memo: "", wantTerms: map[string]int{}, wantLength: 0},
+		{name: "words", memo: "Go ＧＯ, run!", wantTerms: map[string]int{"go": 2, "run": 1}, wantLength: 3},
+		{
+			name:       "japanese characters and pairs",
+			memo:       "散歩した",
+			wantTerms:  map[string]int{"散": 1, "歩": 1, "し": 1, "た": 1, "散歩": 1, "歩し": 1, "した": 1},
+			wantLength: 7,
+		},
+		{
+			name:       "mixed",
+			memo:       "Go言語",
+			wantTerms:  map[string]int{"go": 1, "言": 1, "語": 1, "言語": 1},
+			wantLength: 4,
+		},
+		{
+			name:       "symbols split japanese",
+			memo:       "晴れ。晴れ",
+			wantTerms:  map[string]int{"晴": 2, "れ": 2, "晴れ": 2},
+			wantLength: 6,
+		},
+		{
+			name:       "long words are truncated",
+			memo:       strings.Repeat("a", maxTermLength+8),
+			wantTerms:  map[string]int{strings.Repeat("a", maxTermLength): 1},
+			wantLength: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms, length := indexTerms(tt.memo)
+			if !maps.Equal(terms, tt.wantTerms) {
+				t.Errorf("terms = %v, want %v", terms, tt.wantTerms)
+			}
+			if length != tt.wantLength {
+				t.Errorf("length = %d, want %d", length, tt.wantLength)
+			}
+		})
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		q           string
+		wantTerms   []string
+		wantPhrases []string
+	}{
+		{name: "word", q: "ＧＯ", wantTerms: []string{"go"}, wantPhrases: []string{"go"}},
+		{name: "two characters", q: "散歩", wantTerms: []string{"散歩"}, wantPhrases: []string{"散歩"}},
+		{name: "single character", q: "歩", wantTerms: []string{"歩"}, wantPhrases: []string{"歩"}},
+		{name: "pairs only", q: "東京都", wantTerms: []string{"東京", "京都"}, wantPhrases: []string{"東京都"}},
+		{name: "several words", q: "散歩 go", wantTerms: []string{"散歩", "go"}, wantPhrases: []string{"散歩", "go"}},
+		{name: "duplicate terms", q: "散歩 散歩", wantTerms: []string{"散歩"}, wantPhrases: []string{"散歩", "散歩"}},
+		{name: "symbols only", q: "!? 。", wantTerms: nil, wantPhrases: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSearchQuery(tt.q)
+			if !slices.Equal(got.terms, tt.wantTerms) {
+				t.Errorf("terms = %q, want %q", got.terms, tt.wantTerms)
+			}
+			if !slices.Equal(got.phrases, tt.wantPhrases) {
+				t.Errorf("phrases = %q, want %q", got.phrases, tt.wantPhrases)
+			}
+		})
+	}
+}
+
+func TestSearchQueryMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		memo string
+		want [][2]int
+	}{
+		{name: "every occurrence", q: "散歩", memo: "公園を散歩した。散歩", want: [][2]int{{3, 5}, {8, 10}}},
+		{name: "case and width", q: "go", memo: "Go and ＧＯ", want: [][2]int{{0, 2}, {7, 9}}},
+		{name: "overlapping phrases are merged", q: "東京 京都", memo: "東京都", want: [][2]int{{0, 3}}},
+		{name: "pairs in another order", q: "東京都", memo: "東京と京都", want: nil},
+		{name: "no match", q: "雨", memo: "晴れ", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSearchQuery(tt.q).matches(normalizeText(tt.memo))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+// progressScores は進捗を数値化したもの（平均の算出用）
+var progressScores = map[string]float64{"A": 3, "B": 2, "C": 1}
+
+type TrendOptions struct {
+	Days int
+	// Fill が true の場合、記録のない日（区間）も null の値で返す
+	Fill bool
+	// Rolling は評価の移動平均の日数（0 / 7 / 30）。bucket=day のみ指定できる
+	Rolling int
+	// Bucket は day / week / month（空の場合は day）
+	Bucket string
+}
+
+// trendAccumulator は1日または1区間の値を集計する
+type trendAccumulator struct {
+	entries      int
+	ratingSum    float64
+	progressSum  float64
+	sleepSum     float64
+	sleepNights  int
+	lastProgress string
+
+	// metricIDs は集計する記録項目（nil の場合は記録項目を返さない）
+	metricIDs    []string
+	metricSums   map[string]float64
+	metricCounts map[string]int
+}
+
+// addMetrics は1日分の記録項目の値を加える
+func (a *trendAccumulator) addMetrics(values map[string]float64) {
+	if a.metricSums == nil {
+		a.metricSums, a.metricCounts = map[string]float64{}, map[string]int{}
+	}
+	for id, v := range values {
+		a.metricSums[id] += v
+		a.metricCounts[id]++
+	}
+}
+
+func (a *trendAccumulator) add(d model.Diary, sleepMinutes int, hasSleep bool) {
+	a.entries++
+	a.ratingSum += float64(d.Rating)
+	a.progressSum += progressScores[d.Progress]
+	a.lastProgress = d.Progress
+	if hasSleep {
+		a.sleepSum += float64(sleepMinutes)
+		a.sleepNights++
+	}
+}
+
+func (a *trendAccumulator) entry(date string, daily bool) model.TrendEntry {
+	e := model.TrendEntry{Date: date, Entries: a.entries}
+	if a.metricIDs != nil {
+		e.Metrics = make(map[string]*float64, len(a.metricIDs))
+		for _, id := range a.metricIDs {
+			e.Metrics[id] = nil
+			if n := a.metricCounts[id]; n > 0 {
+				e.Metrics[id] = average(a.metricSums[id], n)
+			}
+		}
+	}
+	if a.entries == 0 {
+		return e
+	}
+	e.Rating = average(a.ratingSum, a.entries)
+	e.ProgressScore = average(a.progressSum, a.entries)
+	if daily {
+		progress := a.lastProgress
+		e.Progress = &progress
+	}
+	if a.sleepNights > 0 {
+		e.SleepDurationMinutes = average(a.sleepSum, a.sleepNights)
+	}
+	return e
+}
+
+func average(sum float64, n int) *float64 {
+	v := math.Round(sum/float64(n)*100) / 100
+	return &v
+}
+
+// bucketStart は date が属する区間の初日を返す（week は月曜始まり）
+func bucketStart(date time.Time, bucket string) time.Time {
+	switch bucket {
+	case "week":
+		offset := (int(date.Weekday()) + 6) % 7
+		return date.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return date
+	}
+}
+
+// GetTrend は直近 opts.Days 日の評価・進捗・睡眠時間の推移を返す
+// 今日の日付は tz（空の場合はユーザー設定）のタイムゾーンで決める。
+func (s *DiaryService) GetTrend(userID, tz string, opts TrendOptions) (*model.TrendData, error) {
+	if opts.Bucket == "" {
+		opts.Bucket = "day"
+	}
+	if opts.Bucket != "day" && opts.Bucket != "week" && opts.Bucket != "month" {
+		return nil, validation("bucket must be one of day, week, month")
+	}
+	if opts.Rolling != 0 && opts.Rolling != 7 && opts.Rolling != 30 {
+		return nil, validation("rolling must be 7 or 30")
+	}
+	if opts.Rolling != 0 && opts.Bucket != "day" {
+		return nil, validation("rolling can only be used with bucket=day")
+	}
+
+	loc, err := s.location(userID, tz)
+	if err != nil {
+		return nil, err
+	}
+	end := today(loc)
+	start := end.AddDate(0, 0, -opts.Days)
+
+	// 初日の睡眠時間には前日の、移動平均には前 Rolling-1 日の記録が必要
+	lookback := 1
+	if opts.Rolling > lookback {
+		lookback = opts.Rolling - 1
+	}
+	diaries, err := s.repo.GetRange(userID, start.AddDate(0, 0, -lookback).Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	byDate := indexByDate(diaries)
+
+	// 記録項目がある場合はその平均も返す
+	metrics, err := s.metrics.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	var metricIDs []string
+	var metricValues map[string]map[string]float64
+	if len(metrics) > 0 {
+		metricIDs = make([]string, len(metrics))
+		for i, m := range metrics {
+			metricIDs[i] = m.ID
+		}
+		metricValues, err = s.metrics.ListValues(userID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data := []model.TrendEntry{}
+	daily := opts.Bucket == "day"
+	var acc *trendAccumulator
+	var accStart time.Time
+
+	flush := func() {
+		if acc != nil && (acc.entries > 0 || opts.Fill) {
+			data = append(data, acc.entry(accStart.Format("2006-01-02"), daily))
+		}
+	}
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if b := bucketStart(day, opts.Bucket); acc == nil || !b.Equal(accStart) {
+			flush()
+			acc = &trendAccumulator{metricIDs: metricIDs}
+			accStart = b
+		}
+
+		date := day.Format("2006-01-02")
+		d, ok := byDate[date]
+		if !ok {
+			continue
+		}
+		sleep, hasSleep := 0, false
+		if prev, ok := byDate[addDays(date, -1)]; ok {
+			sleep, hasSleep = sleepDuration(prev.SleepTime, d.WakeUpTime)
+		}
+		acc.add(d, sleep, hasSleep)
+		acc.addMetrics(metricValues[date])
+	}
+	flush()
+
+	if opts.Rolling > 0 {
+		for i := range data {
+			data[i].RollingAverage = rollingAverage(byDate, data[i].Date, opts.Rolling)
+		}
+	}
+
+	return &model.TrendData{
+		PeriodDays:    opts.Days,
+		Data:          data,
+		StartDate:     start.Format("2006-01-02"),
+		EndDate:       end.Format("2006-01-02"),
+		Bucket:        opts.Bucket,
+		RollingWindow: opts.Rolling,
+	}, nil
+}
+
+// rollingAverage は date までの直近 window 日に記録された評価の平均を返す（記録がない場合は nil）
+func rollingAverage(byDate map[string]model.Diary, date string, window int) *float64 {
+	sum, n := 0.0, 0
+	for i := 0; i < window; i++ {
+		if d, ok := byDate[addDays(date, -i)]; ok {
+			sum += float64(d.Rating)
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return average(sum, n)
+}
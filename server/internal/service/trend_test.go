@@ -0,0 +1,291 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+// trendDiary は評価・進捗・起床時刻だけを変えた日記の作成リクエストを返す
+func trendDiary(date string, rating int, progress, wakeUpTime string) model.CreateDiaryRequest {
+	return model.CreateDiaryRequest{Date: date, Rating: rating, Progress: progress, WakeUpTime: wakeUpTime, SleepTime: "23:00"}
+}
+
+// valueString は nil を null として値を文字列にする
+func valueString(v *float64) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprint(*v)
+}
+
+// trendByDate は推移を日付（区間の初日）で引けるようにする
+func trendByDate(data []model.TrendEntry) map[string]model.TrendEntry {
+	byDate := make(map[string]model.TrendEntry, len(data))
+	for _, e := range data {
+		byDate[e.Date] = e
+	}
+	return byDate
+}
+
+func TestBucketStart(t *testing.T) {
+	tests := []struct {
+		date, bucket, want string
+	}{
+		{date: "2026-03-11", bucket: "day", want: "2026-03-11"},
+		{date: "2026-03-09", bucket: "week", want: "2026-03-09"}, // 月曜
+		{date: "2026-03-11", bucket: "week", want: "2026-03-09"},
+		{date: "2026-03-15", bucket: "week", want: "2026-03-09"}, // 日曜は前の月曜から
+		{date: "2026-01-01", bucket: "week", want: "2025-12-29"}, // 年をまたぐ
+		{date: "2026-03-01", bucket: "month", want: "2026-03-01"},
+		{date: "2026-03-31", bucket: "month", want: "2026-03-01"},
+	}
+
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		if got := bucketStart(date, tt.bucket).Format("2006-01-02"); got != tt.want {
+			t.Errorf("bucketStart(%s, %s) = %s, want %s", tt.date, tt.bucket, got, tt.want)
+		}
+	}
+}
+
+func TestRollingAverage(t *testing.T) {
+	byDate := map[string]model.Diary{
+		"2026-01-01": {Rating: 5},
+		"2026-01-03": {Rating: 1},
+		"2026-01-08": {Rating: 3},
+		"2026-01-31": {Rating: 4},
+	}
+	tests := []struct {
+		date   string
+		window int
+		want   string
+	}{
+		{date: "2026-01-03", window: 7, want: "3"},
+		{date: "2026-01-07", window: 7, want: "3"},
+		{date: "2026-01-08", window: 7, want: "2"}, // 01-01 は窓の外
+		{date: "2026-01-10", window: 7, want: "3"},
+		{date: "2026-01-30", window: 7, want: "null"},
+		{date: "2026-01-30", window: 30, want: "3"},
+		{date: "2026-01-31", window: 30, want: "2.67"},
+	}
+
+	for _, tt := range tests {
+		if got := valueString(rollingAverage(byDate, tt.date, tt.window)); got != tt.want {
+			t.Errorf("rollingAverage(%s, %d) = %s, want %s", tt.date, tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestGetTrendDaily(t *testing.T) {
+	s := newTestDiaryService(t)
+	end := today(time.UTC).Format("2006-01-02")
+	day := func(offset int) string { return addDays(end, offset) }
+	createDiaries(t, s,
+		trendDiary(day(-4), 2, "A", "07:00"), // 期間の前日（初日の睡眠時間にだけ使う）
+		trendDiary(day(-3), 4, "B", "07:30"),
+		trendDiary(day(-1), 5, "A", "06:00"),
+		trendDiary(day(0), 3, "C", "07:00"),
+	)
+
+	type entry struct {
+		date, rating, sleep string
+		entries             int
+	}
+	tests := []struct {
+		name string
+		fill bool
+		want []entry
+	}{
+		{
+			name: "recorded days only",
+			want: []entry{
+				{date: day(-3), rating: "4", sleep: "510", entries: 1},
+				{date: day(-1), rating: "5", sleep: "null", entries: 1},
+				{date: day(0), rating: "3", sleep: "480", entries: 1},
+			},
+		},
+		{
+			name: "fill",
+			fill: true,
+			want: []entry{
+				{date: day(-3), rating: "4", sleep: "510", entries: 1},
+				{date: day(-2), rating: "null", sleep: "null", entries: 0},
+				{date: day(-1), rating: "5", sleep: "null", entries: 1},
+				{date: day(0), rating: "3", sleep: "480", entries: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trend, err := s.GetTrend(testUserID, "UTC", TrendOptions{Days: 3, Fill: tt.fill})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if trend.StartDate != day(-3) || trend.EndDate != end || trend.Bucket != "day" {
+				t.Errorf("range = %s..%s, bucket = %s", trend.StartDate, trend.EndDate, trend.Bucket)
+			}
+			if len(trend.Data) != len(tt.want) {
+				t.Fatalf("entries = %d, want %d", len(trend.Data), len(tt.want))
+			}
+			for i, e := range trend.Data {
+				got := entry{date: e.Date, rating: valueString(e.Rating), sleep: valueString(e.SleepDurationMinutes), entries: e.Entries}
+				if got != tt.want[i] {
+					t.Errorf("entry %d = %+v, want %+v", i, got, tt.want[i])
+				}
+				if (e.Progress != nil) != (e.Entries > 0) {
+					t.Errorf("entry %d: progress = %v with %d entries", i, e.Progress, e.Entries)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTrendRolling(t *testing.T) {
+	end := today(time.UTC).Format("2006-01-02")
+	day := func(offset int) string { return addDays(end, offset) }
+
+	// 期間は昨日と今日の2日。移動平均は期間の前の記録も使う
+	tests := []struct {
+		name    string
+		rolling int
+		diaries []model.CreateDiaryRequest
+		want    [2]string
+	}{
+		{
+			name:    "7 days",
+			rolling: 7,
+			diaries: []model.CreateDiaryRequest{trendDiary(day(-7), 5, "A", "07:00"), trendDiary(day(-6), 1, "A", "07:00"), trendDiary(day(0), 3, "A", "07:00")},
+			want:    [2]string{"3", "2"},
+		},
+		{
+			name:    "30 days",
+			rolling: 30,
+			diaries: []model.CreateDiaryRequest{trendDiary(day(-30), 5, "A", "07:00"), trendDiary(day(-29), 1, "A", "07:00")},
+			want:    [2]string{"3", "1"},
+		},
+		{
+			name:    "no records in the window",
+			rolling: 7,
+			diaries: []model.CreateDiaryRequest{trendDiary(day(-8), 5, "A", "07:00")},
+			want:    [2]string{"null", "null"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestDiaryService(t)
+			createDiaries(t, s, tt.diaries...)
+			trend, err := s.GetTrend(testUserID, "UTC", TrendOptions{Days: 1, Fill: true, Rolling: tt.rolling})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(trend.Data) != 2 {
+				t.Fatalf("entries = %d, want 2", len(trend.Data))
+			}
+			if trend.RollingWindow != tt.rolling {
+				t.Errorf("rolling window = %d, want %d", trend.RollingWindow, tt.rolling)
+			}
+			got := [2]string{valueString(trend.Data[0].RollingAverage), valueString(trend.Data[1].RollingAverage)}
+			if got != tt.want {
+				t.Errorf("rolling averages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTrendBuckets(t *testing.T) {
+	end := today(time.UTC)
+	tests := []struct {
+		bucket string
+		days   int
+		// inside は期間の途中にあり、区間全体が期間に含まれる日
+		inside time.Time
+		// next は区間の初日から次の区間の初日を求める
+		next func(time.Time) time.Time
+	}{
+		{bucket: "week", days: 20, inside: end.AddDate(0, 0, -13), next: func(d time.Time) time.Time { return d.AddDate(0, 0, 7) }},
+		{bucket: "month", days: 100, inside: end.AddDate(0, 0, -60), next: func(d time.Time) time.Time { return d.AddDate(0, 1, 0) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bucket, func(t *testing.T) {
+			s := newTestDiaryService(t)
+			start := end.AddDate(0, 0, -tt.days)
+			first := bucketStart(tt.inside, tt.bucket)
+			last := tt.next(first).AddDate(0, 0, -1)
+			format := func(d time.Time) string { return d.Format("2006-01-02") }
+			createDiaries(t, s,
+				// 期間の前日は、初日と同じ区間でも数えない
+				trendDiary(format(start.AddDate(0, 0, -1)), 1, "C", "07:00"),
+				trendDiary(format(start), 4, "A", "07:00"),
+				trendDiary(format(first), 2, "A", "07:00"),
+				trendDiary(format(last), 5, "C", "07:00"),
+			)
+
+			trend, err := s.GetTrend(testUserID, "UTC", TrendOptions{Days: tt.days, Fill: true, Bucket: tt.bucket})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// 最初の区間は期間の初日より前から始まることがある
+			if want := format(bucketStart(start, tt.bucket)); trend.Data[0].Date != want {
+				t.Errorf("first bucket = %s, want %s", trend.Data[0].Date, want)
+			}
+			if want := format(bucketStart(end, tt.bucket)); trend.Data[len(trend.Data)-1].Date != want {
+				t.Errorf("last bucket = %s, want %s", trend.Data[len(trend.Data)-1].Date, want)
+			}
+			for i := 1; i < len(trend.Data); i++ {
+				prev, _ := time.Parse("2006-01-02", trend.Data[i-1].Date)
+				if want := format(tt.next(prev)); trend.Data[i].Date != want {
+					t.Errorf("bucket %d = %s, want %s", i, trend.Data[i].Date, want)
+				}
+			}
+
+			byDate := trendByDate(trend.Data)
+			if e := byDate[format(bucketStart(start, tt.bucket))]; e.Entries != 1 || valueString(e.Rating) != "4" {
+				t.Errorf("first bucket: entries = %d, rating = %s, want 1 and 4", e.Entries, valueString(e.Rating))
+			}
+			e := byDate[format(first)]
+			if e.Entries != 2 || valueString(e.Rating) != "3.5" || valueString(e.ProgressScore) != "2" || e.Progress != nil {
+				t.Errorf("bucket %s: entries = %d, rating = %s, progress score = %s, progress = %v",
+					e.Date, e.Entries, valueString(e.Rating), valueString(e.ProgressScore), e.Progress)
+			}
+
+			// Fill がない場合は記録のある区間だけ
+			trend, err = s.GetTrend(testUserID, "UTC", TrendOptions{Days: tt.days, Bucket: tt.bucket})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(trend.Data) != 2 {
+				t.Errorf("buckets without fill = %d, want 2", len(trend.Data))
+			}
+		})
+	}
+}
+
+func TestGetTrendValidation(t *testing.T) {
+	s := newTestDiaryService(t)
+	tests := []struct {
+		name    string
+		opts    TrendOptions
+		wantErr string
+	}{
+		{name: "unknown bucket", opts: TrendOptions{Days: 7, Bucket: "year"}, wantErr: "bucket must be one of day, week, month"},
+		{name: "unsupported window", opts: TrendOptions{Days: 7, Rolling: 14}, wantErr: "rolling must be 7 or 30"},
+		{name: "rolling with buckets", opts: TrendOptions{Days: 7, Rolling: 7, Bucket: "week"}, wantErr: "rolling can only be used with bucket=day"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GetTrend(testUserID, "UTC", tt.opts)
+			if !errors.Is(err, ErrValidation) || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+	"github.com/nana743533/260219-diary-app/server/internal/repository"
+)
+
+var errUserNotFound = notFound("User not found")
+
+type UserService struct {
+	users repository.UserRepository
+}
+
+func NewUserService(users repository.UserRepository) *UserService {
+	return &UserService{users: users}
+}
+
+func (s *UserService) GetSettings(userID string) (*model.UserSettings, error) {
+	settings, err := s.users.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, errUserNotFound
+	}
+	return settings, nil
+}
+
+// UpdateSettings は指定された項目のみ更新する
+func (s *UserService) UpdateSettings(userID string, req model.UpdateSettingsRequest) (*model.UserSettings, error) {
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SleepTargetMinutes != nil {
+		settings.SleepTargetMinutes = *req.SleepTargetMinutes
+	}
+	if req.Timezone != nil {
+		settings.Timezone = *req.Timezone
+	}
+
+	updated, err := s.users.UpdateSettings(userID, settings)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, errUserNotFound
+	}
+	return settings, nil
+}
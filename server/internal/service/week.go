@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nana743533/260219-diary-app/server/internal/model"
+)
+
+// isoWeekStart は ISO 8601 の year 年第 week 週の月曜日を返す。存在しない週の場合は ok=false。
+func isoWeekStart(year, week int) (time.Time, bool) {
+	// 1月4日を含む週が第1週
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, false
+	}
+	return monday, true
+}
+
+// GetWeek は ISO 8601 の year 年第 week 週の各日の日記と週の集計を返す
+func (s *DiaryService) GetWeek(userID string, year, week int) (*model.WeekView, error) {
+	if year < 1900 || year > 9999 {
+		return nil, validation("year must be between 1900 and 9999")
+	}
+	start, ok := isoWeekStart(year, week)
+	if !ok {
+		return nil, validation(fmt.Sprintf("Week %d does not exist in %d", week, year))
+	}
+	return s.weekView(userID, start)
+}
+
+// GetWeekOf は date を含む週を返す（date が空の場合は tz のタイムゾーンでの今日）
+func (s *DiaryService) GetWeekOf(userID, tz, date string) (*model.WeekView, error) {
+	if date == "" {
+		loc, err := s.location(userID, tz)
+		if err != nil {
+			return nil, err
+		}
+		date = today(loc).Format("2006-01-02")
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, validation("date must be YYYY-MM-DD")
+	}
+	return s.weekView(userID, bucketStart(day, "week"))
+}
+
+func (s *DiaryService) weekView(userID string, start time.Time) (*model.WeekView, error) {
+	end := start.AddDate(0, 0, 6)
+	startDate, endDate := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	// 月曜日の睡眠時間を求めるため前日分から取得する
+	diaries, err := s.repo.GetRange(userID, addDays(startDate, -1), endDate)
+	if err != nil {
+		return nil, err
+	}
+	byDate := indexByDate(diaries)
+	attachSleepDurations(diaries, byDate)
+	if err := s.withTags(diaries); err != nil {
+		return nil, err
+	}
+	if err := s.withMetrics(userID, diaries); err != nil {
+		return nil, err
+	}
+
+	year, week := start.ISOWeek()
+	view := &model.WeekView{
+		Year:      year,
+		Week:      week,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Days:      make([]model.WeekDay, 0, 7),
+	}
+
+	group := newPatternGroup("")
+	sleepSum, sleepNights := 0, 0
+	for _, d := range diaries {
+		if d.Date < startDate {
+			continue
+		}
+		group.add(d)
+		if d.SleepDurationMinutes != nil {
+			sleepSum += *d.SleepDurationMinutes
+			sleepNights++
+		}
+	}
+	// 睡眠時間を設定した日記で引き直す
+	byDate = indexByDate(diaries)
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		wd := model.WeekDay{Date: date, Weekday: int(day.Weekday())}
+		if d, ok := byDate[date]; ok {
+			wd.Diary = &d
+		}
+		view.Days = append(view.Days, wd)
+	}
+
+	result := group.result()
+	view.Summary = model.WeekSummary{
+		TotalDays:            7,
+		RecordedDays:         result.TotalEntries,
+		AverageRating:        result.AverageRating,
+		ProgressDistribution: result.ProgressDistribution,
+		AverageWakeUpTime:    result.AverageWakeUpTime,
+		AverageSleepTime:     result.AverageSleepTime,
+	}
+	if sleepNights > 0 {
+		view.Summary.AverageSleepDurationMinutes = average(float64(sleepSum), sleepNights)
+	}
+
+	return view, nil
+}